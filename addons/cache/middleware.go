@@ -17,16 +17,10 @@ type CachedResponse struct {
 	Body       []byte
 }
 
-// responseRecorder captures the response status, headers, and body for caching.
+// responseRecorder captures the response body for caching.
 type responseRecorder struct {
 	http.ResponseWriter
-	statusCode int
-	body       *bytes.Buffer
-}
-
-func (r *responseRecorder) WriteHeader(statusCode int) {
-	r.statusCode = statusCode
-	r.ResponseWriter.WriteHeader(statusCode)
+	body *bytes.Buffer
 }
 
 func (r *responseRecorder) Write(b []byte) (int, error) {
@@ -34,6 +28,11 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush or hijack.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // KeyGenerator allows customizing the cache key.
 type KeyGenerator func(c *amaro.Context) string
 
@@ -80,21 +79,22 @@ func CachePage(store Cache, ttl time.Duration, keyGen ...KeyGenerator) amaro.Mid
 			}
 
 			// Miss
+			rw := c.Response()
 			recorder := &responseRecorder{
-				ResponseWriter: c.Writer,
-				statusCode:     http.StatusOK, // Default
+				ResponseWriter: rw.Writer,
 				body:           &bytes.Buffer{},
 			}
-			c.Writer = recorder
+			rw.Writer = recorder
 
 			// Process request
 			err := next(c)
+			rw.Writer = recorder.ResponseWriter
 
-			// If successful, cache the result
-			if err == nil && recorder.statusCode < 400 {
+			// If successful, cache the result; hijacked connections have no response to replay
+			if err == nil && rw.Status() < 400 && rw.Status() != http.StatusSwitchingProtocols {
 				// Create cached response
 				resp := CachedResponse{
-					StatusCode: recorder.statusCode,
+					StatusCode: rw.Status(),
 					Headers:    recorder.Header().Clone(), // Copy headers
 					Body:       recorder.body.Bytes(),
 				}
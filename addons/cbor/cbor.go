@@ -0,0 +1,53 @@
+// Package cbor adds CBOR (RFC 8949) to the content negotiation and binding of amaro.
+// It wraps github.com/fxamacker/cbor/v2.
+package cbor
+
+import (
+	"errors"
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// ContentType is the media type of CBOR.
+const ContentType = "application/cbor"
+
+// Encode is an amaro.Encoder writing v as CBOR.
+func Encode(w io.Writer, v interface{}) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
+
+// Decode is an amaro.Decoder reading CBOR into v.
+func Decode(r io.Reader, v interface{}) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
+
+// Enable returns an AppOption that lets Negotiate respond with CBOR and Bind read CBOR bodies.
+//
+//	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), cbor.Enable())
+func Enable() amaro.AppOption {
+	encoder := amaro.WithEncoder(ContentType, Encode)
+	decoder := amaro.WithDecoder(ContentType, Decode)
+	return func(app *amaro.App) {
+		encoder(app)
+		decoder(app)
+	}
+}
+
+// Render writes v encoded as CBOR.
+func Render(c *amaro.Context, code int, v interface{}) error {
+	return c.Encode(code, ContentType, Encode, v)
+}
+
+// Bind decodes the CBOR request body into v and validates it like amaro.Context.Bind.
+func Bind(c *amaro.Context, v interface{}) error {
+	if c.Request.Body == nil {
+		return errors.New("request body is empty")
+	}
+	if err := Decode(c.Request.Body, v); err != nil {
+		return err
+	}
+	return amaro.ValidateStruct(v)
+}
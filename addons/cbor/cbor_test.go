@@ -0,0 +1,77 @@
+package cbor_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fxcbor "github.com/fxamacker/cbor/v2"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/addons/cbor"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+type item struct {
+	ID   int    `cbor:"id"`
+	Name string `cbor:"name" validate:"required"`
+}
+
+func TestEnable(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), cbor.Enable())
+	app.POST("/items", func(c *amaro.Context) error {
+		var v item
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.Negotiate(http.StatusCreated, v)
+	})
+
+	body, err := fxcbor.Marshal(item{ID: 3, Name: "gear"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body))
+	req.Header.Set("Content-Type", cbor.ContentType)
+	req.Header.Set("Accept", "text/*;q=0.8, application/cbor")
+	w := app.Test(req)
+
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != cbor.ContentType {
+		t.Fatalf("Expected 201 CBOR, got %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	var got item
+	if err := fxcbor.Unmarshal(w.Body.Bytes(), &got); err != nil || got != (item{ID: 3, Name: "gear"}) {
+		t.Errorf("Expected the item back, got %+v, %v", got, err)
+	}
+
+	// JSON stays the default
+	req = httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body))
+	req.Header.Set("Content-Type", cbor.ContentType)
+	if w := app.Test(req); w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON without Accept, got %q", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestRenderAndBind(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.POST("/items", func(c *amaro.Context) error {
+		var v item
+		if err := cbor.Bind(c, &v); err != nil {
+			return err
+		}
+		return cbor.Render(c, http.StatusOK, v)
+	})
+
+	body, _ := fxcbor.Marshal(item{ID: 4})
+	w := app.Test(httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body)))
+	if w.Code == http.StatusOK {
+		t.Error("Expected missing name to fail validation")
+	}
+
+	body, _ = fxcbor.Marshal(item{ID: 4, Name: "bolt"})
+	w = app.Test(httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body)))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != cbor.ContentType || !bytes.Equal(w.Body.Bytes(), body) {
+		t.Errorf("Expected the CBOR body back, got %d %q", w.Code, w.Body.Bytes())
+	}
+}
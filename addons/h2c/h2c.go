@@ -0,0 +1,27 @@
+// Package h2c serves HTTP/2 over cleartext (h2c), both with prior knowledge and after an
+// HTTP/1.1 Upgrade, e.g. for gRPC-gateway behind a service mesh.
+// It wraps golang.org/x/net/http2/h2c.
+package h2c
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// ConfigureServer is an amaro.ServerConfig.ConfigureServer hook that serves h2c on the
+// listeners without TLS. The HTTP2 settings of the ServerConfig apply to h2c as well.
+//
+//	config := amaro.DefaultServerConfig()
+//	config.ConfigureServer = h2c.ConfigureServer
+//	app := amaro.New(amaro.WithServerConfig(config))
+func ConfigureServer(srv *http.Server, tls bool) error {
+	if tls {
+		return nil
+	}
+	h2s := &http2.Server{}
+	srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+	// Registers h2s for graceful shutdown
+	return http2.ConfigureServer(srv, h2s)
+}
@@ -0,0 +1,120 @@
+package h2c_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/addons/h2c"
+	"github.com/buildwithgo/amaro/routers"
+	"golang.org/x/net/http2"
+)
+
+func TestConfigureServer(t *testing.T) {
+	config := amaro.DefaultServerConfig()
+	config.Signals = nil
+	config.ConfigureServer = h2c.ConfigureServer
+	config.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: 42}
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithServerConfig(config),
+	)
+	app.GET("/proto", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, c.Request.Proto)
+	})
+
+	started := make(chan struct{})
+	app.OnStart(func() error {
+		close(started)
+		return nil
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Listener(l)
+	}()
+	<-started
+	defer func() {
+		app.Shutdown(context.Background())
+		<-done
+	}()
+
+	t.Run("PriorKnowledge", func(t *testing.T) {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		io.WriteString(conn, http2.ClientPreface)
+
+		framer := http2.NewFramer(conn, conn)
+		if err := framer.WriteSettings(); err != nil {
+			t.Fatal(err)
+		}
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings, ok := frame.(*http2.SettingsFrame)
+		if !ok {
+			t.Fatalf("Expected SETTINGS frame, got %v", frame)
+		}
+		if v, _ := settings.Value(http2.SettingMaxConcurrentStreams); v != 42 {
+			t.Errorf("Expected MaxConcurrentStreams 42, got %d", v)
+		}
+
+		client := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}}
+		resp, err := client.Get("http://" + l.Addr().String() + "/proto")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "HTTP/2.0" {
+			t.Errorf("Expected HTTP/2.0, got %q", body)
+		}
+	})
+
+	t.Run("Upgrade", func(t *testing.T) {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		io.WriteString(conn, "GET /proto HTTP/1.1\r\nHost: test\r\n"+
+			"Connection: Upgrade, HTTP2-Settings\r\nUpgrade: h2c\r\nHTTP2-Settings: AAMAAABkAARAAAAAAAIAAAAA\r\n\r\n")
+
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Upgrade") != "h2c" {
+			t.Errorf("Expected 101 with Upgrade h2c, got %d %q", resp.StatusCode, resp.Header.Get("Upgrade"))
+		}
+	})
+
+	t.Run("HTTP1", func(t *testing.T) {
+		resp, err := http.Get("http://" + l.Addr().String() + "/proto")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "HTTP/1.1" {
+			t.Errorf("Expected HTTP/1.1, got %q", body)
+		}
+	})
+}
@@ -50,3 +50,15 @@ func Retarget(c *amaro.Context, target string) {
 func Reswap(c *amaro.Context, swap string) {
 	c.SetHeader("HX-Reswap", swap)
 }
+
+// Render renders the named page with c.Render, or only its fragment block for htmx requests
+// that are not boosted, e.g. Render(c, 200, "users/index", "list", data) renders
+// "users/index#list" to swap in the list. Since the response depends on the HX-Request header
+// it adds it to Vary.
+func Render(c *amaro.Context, code int, page, fragment string, data any) error {
+	c.Writer.Header().Add("Vary", "HX-Request")
+	if Is(c) && c.GetHeader("HX-Boosted") != "true" {
+		return c.Render(code, page+"#"+fragment, data)
+	}
+	return c.Render(code, page, data)
+}
@@ -2,11 +2,14 @@ package htmx_test
 
 import (
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 
 	"github.com/buildwithgo/amaro"
 	"github.com/buildwithgo/amaro/addons/htmx"
+	"github.com/buildwithgo/amaro/routers"
 )
 
 func TestHTMX(t *testing.T) {
@@ -84,3 +87,45 @@ func TestHTMX(t *testing.T) {
 		}
 	})
 }
+
+func TestRender(t *testing.T) {
+	renderer, err := amaro.NewTemplateRenderer(amaro.TemplateConfig{
+		Root: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`<body>{{block "list" .}}{{end}}</body>`)},
+			"users.html":        {Data: []byte(`{{define "list"}}<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}`)},
+		},
+		Layout: "layouts/base",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), amaro.WithRenderer(renderer))
+	app.GET("/users", func(c *amaro.Context) error {
+		return htmx.Render(c, http.StatusOK, "users", "list", []string{"ada"})
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+	}{
+		{"Page", nil, "<body><ul><li>ada</li></ul></body>"},
+		{"Fragment", map[string]string{"HX-Request": "true"}, "<ul><li>ada</li></ul>"},
+		{"Boosted", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "<body><ul><li>ada</li></ul></body>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/users", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := app.Test(req)
+			if w.Body.String() != tt.body {
+				t.Errorf("Expected %q, got %q", tt.body, w.Body.String())
+			}
+			if w.Header().Get("Vary") != "HX-Request" {
+				t.Errorf("Expected Vary: HX-Request, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
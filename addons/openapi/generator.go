@@ -4,11 +4,17 @@ import (
 	"reflect"
 	"strings"
 	"time"
+
+	"github.com/buildwithgo/amaro"
 )
 
 // Generator holds the OpenAPI specification and provides methods to add routes and schemas.
 type Generator struct {
 	Spec *OpenAPI
+
+	// SecurityScheme is the name of the security scheme that route scopes are documented under.
+	// Route scopes are not documented when empty.
+	SecurityScheme string
 }
 
 func NewGenerator(info Info) *Generator {
@@ -48,6 +54,108 @@ func (g *Generator) AddRoute(method, path string, op Operation) {
 	}
 }
 
+// AddRoutes documents routes using their metadata (tags, description, scopes, deprecation).
+// Typically called with app.Routes(). Operations already added for a route, e.g. by WrapHandler,
+// keep their schemas and gain the route metadata.
+func (g *Generator) AddRoutes(routes []amaro.Route) {
+	for _, route := range routes {
+		path, params := convertPath(route.Path)
+
+		op := &Operation{Responses: map[string]*Response{}}
+		if item := g.Spec.Paths[path]; item != nil {
+			if existing := item.operation(route.Method); existing != nil {
+				op = existing
+			}
+		}
+
+		op.OperationID = route.Name
+		if len(route.Meta.Tags) > 0 {
+			op.Tags = route.Meta.Tags
+		}
+		if route.Meta.Description != "" {
+			op.Description = route.Meta.Description
+		}
+		if op.Summary == "" {
+			op.Summary = path
+		}
+		op.Deprecated = route.Meta.Deprecated
+		if g.SecurityScheme != "" && len(route.Meta.Scopes) > 0 {
+			op.Security = []map[string][]string{{g.SecurityScheme: route.Meta.Scopes}}
+		}
+		if len(op.Parameters) == 0 {
+			for _, name := range params {
+				op.Parameters = append(op.Parameters, &Parameter{
+					Name:     name,
+					In:       "path",
+					Required: true,
+					Schema:   &Schema{Type: "string"},
+				})
+			}
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["200"] = &Response{Description: "OK"}
+		}
+
+		g.AddRoute(route.Method, path, *op)
+	}
+}
+
+// operation returns the operation registered for method, or nil.
+func (item *PathItem) operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return item.Get
+	case "POST":
+		return item.Post
+	case "PUT":
+		return item.Put
+	case "DELETE":
+		return item.Delete
+	case "PATCH":
+		return item.Patch
+	case "OPTIONS":
+		return item.Options
+	case "HEAD":
+		return item.Head
+	}
+	return nil
+}
+
+// convertPath rewrites amaro :param, {param}, :param?, multi-param and *wildcard segments
+// to OpenAPI {param} syntax. It returns the converted path and the parameter names in order.
+func convertPath(path string) (string, []string) {
+	config := amaro.DefaultRouterConfig()
+	segments := strings.Split(path, "/")
+	var params []string
+	param := func(value string) string {
+		name, _ := config.ConstraintParser(value)
+		params = append(params, name)
+		return "{" + name + "}"
+	}
+
+	for i, segment := range segments {
+		if stripped, ok := config.OptionalParser(segment); ok && config.IsParam(stripped) {
+			segment = stripped
+		}
+		if parts := config.SegmentParser(segment); parts != nil {
+			var b strings.Builder
+			for _, part := range parts {
+				if part.Param {
+					b.WriteString(param(part.Value))
+				} else {
+					b.WriteString(part.Value)
+				}
+			}
+			segments[i] = b.String()
+		} else if ok, name := config.ParamParser(segment); ok {
+			segments[i] = param(name)
+		} else if ok, name := config.WildcardParser(segment); ok {
+			segments[i] = param(name)
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
 // GenerateSchema creates a schema for v and registers it in Components if it's a struct
 func (g *Generator) GenerateSchema(v interface{}) *Schema {
 	t := reflect.TypeOf(v)
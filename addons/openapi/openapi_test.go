@@ -114,3 +114,49 @@ func TestOpenAPIIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestAddRoutes(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	gen := openapi.NewGenerator(openapi.Info{Title: "Test API", Version: "1.0.0"})
+	gen.SecurityScheme = "oauth2"
+
+	createHandler := func(c *amaro.Context, req *CreateUserRequest) (*UserResponse, error) {
+		return &UserResponse{ID: "1", Name: req.Name}, nil
+	}
+	app.Handle(http.MethodPost, "/users", openapi.WrapHandler(gen, "POST", "/users", createHandler),
+		amaro.WithTags("users"),
+	)
+	app.Handle(http.MethodGet, "/users/:id", func(c *amaro.Context) error { return nil },
+		amaro.WithName("getUser"),
+		amaro.WithTags("users"),
+		amaro.WithDescription("Fetch a user"),
+		amaro.WithScopes("users:read"),
+		amaro.WithDeprecated(),
+	)
+
+	gen.AddRoutes(app.Routes())
+
+	item, ok := gen.Spec.Paths["/users/{id}"]
+	if !ok || item.Get == nil {
+		t.Fatal("Expected GET /users/{id} operation")
+	}
+	op := item.Get
+	if op.OperationID != "getUser" || op.Description != "Fetch a user" || !op.Deprecated {
+		t.Errorf("Unexpected operation: %+v", op)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+		t.Errorf("Expected id path parameter, got %+v", op.Parameters)
+	}
+	if len(op.Security) != 1 || op.Security[0]["oauth2"][0] != "users:read" {
+		t.Errorf("Expected oauth2 users:read security, got %+v", op.Security)
+	}
+
+	// Existing typed operation keeps its schema and gains tags
+	post := gen.Spec.Paths["/users"].Post
+	if post.RequestBody == nil {
+		t.Error("Expected request body to be preserved")
+	}
+	if len(post.Tags) != 1 || post.Tags[0] != "users" {
+		t.Errorf("Expected tags [users], got %v", post.Tags)
+	}
+}
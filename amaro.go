@@ -2,18 +2,16 @@
 package amaro
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"io/fs"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"os/signal"
+	"path"
+	"slices"
 	"strings"
 	"sync"
-	"syscall"
-	"time"
 )
 
 // Handler is a function that handles an HTTP request.
@@ -35,6 +33,37 @@ type App struct {
 	handler      Handler
 	once         sync.Once
 	errorHandler ErrorHandler
+	server       ServerConfig
+	lifecycle    lifecycle
+	encoders     []contentEncoder   // nil uses defaultEncoders
+	decoders     map[string]Decoder // nil uses defaultDecoders
+	renderer     Renderer
+	cleanPaths   bool    // rewrite unclean request paths, see RouterConfig.CleanPath
+	errs         []error // configuration errors reported by Validate
+
+	namesMu      sync.RWMutex
+	names        map[string]string // route name -> path pattern
+	namesVersion uint64            // router version when names was built, see VersionedRouter
+}
+
+// DefaultErrorHandler writes err as plain text. The status code and message of an
+// HTTPError take precedence over code. A ProblemDetails is written as problem+json.
+func DefaultErrorHandler(c *Context, err error, code int) {
+	var pd *ProblemDetails
+	if errors.As(err, &pd) {
+		writeProblem(c, Problem(pd, code))
+		return
+	}
+	if he, ok := err.(*HTTPError); ok {
+		code = he.Code
+		if msg, ok := he.Message.(string); ok {
+			http.Error(c.Writer, msg, code)
+		} else {
+			http.Error(c.Writer, http.StatusText(code), code)
+		}
+		return
+	}
+	http.Error(c.Writer, err.Error(), code)
 }
 
 // WithErrorHandler returns an AppOption that configures the App to use the specified ErrorHandler.
@@ -44,43 +73,53 @@ func WithErrorHandler(handler ErrorHandler) AppOption {
 	}
 }
 
+// handleError passes err to the configured ErrorHandler, or to DefaultErrorHandler.
+func (a *App) handleError(c *Context, err error, code int) {
+	if a.errorHandler != nil {
+		a.errorHandler(c, err, code)
+		return
+	}
+	DefaultErrorHandler(c, err, code)
+}
+
 // Use adds a global middleware to the application.
 // Global middlewares are applied to all routes in the order they are added.
 func (a *App) Use(middleware Middleware) {
 	a.middlewares = append(a.middlewares, middleware)
 }
 
-// GET registers a new GET route with a handler and optional route-specific middlewares.
-func (a *App) GET(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodGet, path, handler, middlewares...)
+// GET registers a new GET route with a handler and optional route-specific middlewares
+// and RouteOptions.
+func (a *App) GET(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodGet, path, handler, options...)
 }
 
-func (a *App) POST(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodPost, path, handler, middlewares...)
+func (a *App) POST(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodPost, path, handler, options...)
 }
 
-func (a *App) PUT(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodPut, path, handler, middlewares...)
+func (a *App) PUT(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodPut, path, handler, options...)
 }
 
-func (a *App) DELETE(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodDelete, path, handler, middlewares...)
+func (a *App) DELETE(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodDelete, path, handler, options...)
 }
 
-func (a *App) PATCH(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodPatch, path, handler, middlewares...)
+func (a *App) PATCH(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodPatch, path, handler, options...)
 }
 
-func (a *App) OPTIONS(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodOptions, path, handler, middlewares...)
+func (a *App) OPTIONS(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodOptions, path, handler, options...)
 }
 
-func (a *App) HEAD(path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(http.MethodHead, path, handler, middlewares...)
+func (a *App) HEAD(path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(http.MethodHead, path, handler, options...)
 }
 
 // Any registers a route that matches all standard HTTP methods.
-func (a *App) Any(path string, handler Handler, middlewares ...Middleware) error {
+func (a *App) Any(path string, handler Handler, options ...RouteOption) error {
 	methods := []string{
 		http.MethodGet,
 		http.MethodPost,
@@ -91,7 +130,7 @@ func (a *App) Any(path string, handler Handler, middlewares ...Middleware) error
 		http.MethodHead,
 	}
 	for _, method := range methods {
-		if err := a.Add(method, path, handler, middlewares...); err != nil {
+		if err := a.Handle(method, path, handler, options...); err != nil {
 			return err
 		}
 	}
@@ -100,8 +139,9 @@ func (a *App) Any(path string, handler Handler, middlewares ...Middleware) error
 
 // Mount registers an http.Handler (e.g., grpc-gateway mux) at the specified path prefix.
 // It registers the handler for all standard HTTP methods for the exact path and all subpaths.
-func (a *App) Mount(path string, handler http.Handler) error {
-	h := WrapHTTPHandler(handler)
+// With WithStripPrefix the handler sees paths relative to the prefix.
+func (a *App) Mount(path string, handler http.Handler, options ...MountOption) error {
+	h := mountHandler(path, handler, options)
 
 	// Exact match
 	if err := a.Any(path, h); err != nil {
@@ -118,15 +158,100 @@ func (a *App) Mount(path string, handler http.Handler) error {
 	return a.Any(wildcardPath, h)
 }
 
-// Add registers a new route with the specified method, path, handler, and middlewares.
-func (a *App) Add(method, path string, handler Handler, middlewares ...Middleware) error {
-	return a.router.Add(method, path, handler, middlewares...)
+// Add registers a new route with the specified method, path, handler, and middlewares
+// or RouteOptions.
+func (a *App) Add(method, path string, handler Handler, options ...RouteOption) error {
+	return a.Handle(method, path, handler, options...)
+}
+
+// Handle registers a new route configured by the given RouteOptions.
+//
+//	app.Handle(http.MethodGet, "/users/:id", show, amaro.WithName("user.show"))
+func (a *App) Handle(method, path string, handler Handler, options ...RouteOption) error {
+	route := Route{Method: method, Path: path, Handler: handler}
+	for _, option := range options {
+		option.applyRoute(&route)
+	}
+	return registerRoute(a.router, route)
+}
+
+// URL builds the path of the route registered under name.
+// Params fill the route's parameters and wildcard in the order they appear.
+func (a *App) URL(name string, params ...interface{}) (string, error) {
+	pattern, ok := a.lookupName(name)
+	if !ok {
+		return "", fmt.Errorf("route %q not found", name)
+	}
+	config := DefaultRouterConfig()
+	if cr, ok := a.router.(ConfigurableRouter); ok {
+		config = cr.Config()
+	}
+	return config.BuildPath(pattern, params...)
+}
+
+// lookupName returns the path pattern of the named route.
+// The index is built from Routes() and rebuilt once the router version has changed,
+// or when name is missing if the router is not a VersionedRouter.
+func (a *App) lookupName(name string) (string, bool) {
+	vr, versioned := a.router.(VersionedRouter)
+	var version uint64
+	if versioned {
+		version = vr.Version()
+	}
+
+	a.namesMu.RLock()
+	pattern, ok := a.names[name]
+	fresh := a.names != nil && a.namesVersion == version && (versioned || ok)
+	a.namesMu.RUnlock()
+	if fresh {
+		return pattern, ok
+	}
+
+	a.namesMu.Lock()
+	defer a.namesMu.Unlock()
+	a.names = make(map[string]string)
+	for _, route := range a.router.Routes() {
+		if route.Name != "" {
+			a.names[route.Name] = route.Path
+		}
+	}
+	a.namesVersion = version
+	pattern, ok = a.names[name]
+	return pattern, ok
 }
 
 func (a *App) Group(prefix string) *Group {
 	return a.router.Group(prefix)
 }
 
+// ListenerGroup returns a group whose routes are only served by the named listeners
+// started by Serve, such as an admin port.
+//
+//	admin := app.ListenerGroup("admin")
+//	admin.GET("/metrics", metrics)
+func (a *App) ListenerGroup(names ...string) *Group {
+	return a.Group("").ListenerGroup(names...)
+}
+
+// Host returns a group whose routes only match requests for hosts matching pattern,
+// such as "api.example.com" or "{tenant}.example.com". Host params are read through PathParam.
+// If the router does not implement HostRouter, registrations on the group fail and
+// Validate reports the error. Invalid patterns are reported by the router's Validate.
+//
+//	tenant := app.Host("{tenant}.example.com")
+//	tenant.GET("/", func(c *amaro.Context) error {
+//		return c.String(http.StatusOK, c.PathParam("tenant"))
+//	})
+func (a *App) Host(pattern string) *Group {
+	hr, ok := a.router.(HostRouter)
+	if !ok {
+		err := fmt.Errorf("host %s: %w", pattern, ErrHostRoutingUnsupported)
+		a.errs = append(a.errs, err)
+		return NewGroup("", failingRouter{Router: a.router, err: err})
+	}
+	return hr.Host(pattern)
+}
+
 func (a *App) StaticFS(pathPrefix string, fs fs.FS) {
 	a.router.StaticFS(pathPrefix, fs)
 }
@@ -136,6 +261,37 @@ func (a *App) Static(pathPrefix, root string) {
 	a.StaticFS(pathPrefix, os.DirFS(root))
 }
 
+// Validate reports all failed route registrations, including those whose error was
+// ignored, host patterns that could not be used and route names used for different paths.
+// Run and RunTLS call it before serving.
+// Registration errors wrap a RouteError; conflicts also match ErrRouteConflict with errors.Is.
+func (a *App) Validate() error {
+	errs := a.errs[:len(a.errs):len(a.errs)]
+	if v, ok := a.router.(ValidatingRouter); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	names := make(map[string]Route)
+	for _, route := range a.router.Routes() {
+		if route.Name == "" {
+			continue
+		}
+		if other, ok := names[route.Name]; ok && (other.Path != route.Path || other.Host != route.Host) {
+			errs = append(errs, fmt.Errorf("%w: route name %q used by %s and %s", ErrRouteConflict, route.Name, other.Path, route.Path))
+			continue
+		}
+		names[route.Name] = route
+	}
+	return errors.Join(errs...)
+}
+
+// Routes returns all registered routes, including their names and metadata.
+func (a *App) Routes() []Route {
+	return a.router.Routes()
+}
+
 func (a *App) Find(method, path string) (*Route, error) {
 	return a.router.Find(method, path, nil)
 }
@@ -155,24 +311,16 @@ type AppOption func(*App)
 func New(options ...AppOption) *App {
 	app := &App{
 		middlewares: []Middleware{Recovery()}, // Add Recovery middleware by default
-		pool: &sync.Pool{
-			New: func() interface{} {
-				// We can't fully init here because we need w/r, but we create the struct
-				// The slice capacity is set in context.go
-				return NewContext(nil, nil)
-			},
-		},
-		errorHandler: func(c *Context, err error, code int) {
-			if he, ok := err.(*HTTPError); ok {
-				code = he.Code
-				if msg, ok := he.Message.(string); ok {
-					http.Error(c.Writer, msg, code)
-				} else {
-					http.Error(c.Writer, http.StatusText(code), code)
-				}
-				return
-			}
-			http.Error(c.Writer, err.Error(), code)
+		server:      DefaultServerConfig(),
+	}
+
+	app.pool = &sync.Pool{
+		New: func() interface{} {
+			// We can't fully init here because we need w/r, but we create the struct
+			// The slice capacity is set in context.go
+			ctx := NewContext(nil, nil)
+			ctx.app = app
+			return ctx
 		},
 	}
 
@@ -183,69 +331,15 @@ func New(options ...AppOption) *App {
 	return app
 }
 
-// Run starts the HTTP server with graceful shutdown support.
+// Run starts the HTTP server on address with graceful shutdown support.
+// A bare port such as "8080" listens on all interfaces.
 func (a *App) Run(address string) error {
-	return a.startServer(address, "", "")
+	return a.listen(address, "", "")
 }
 
 // RunTLS starts the HTTPS server with graceful shutdown support.
 func (a *App) RunTLS(address, certFile, keyFile string) error {
-	return a.startServer(address, certFile, keyFile)
-}
-
-func (a *App) startServer(address, certFile, keyFile string) error {
-	if !strings.HasPrefix(address, ":") {
-		address = ":" + address
-	}
-
-	// We do NOT modify a.middlewares here anymore.
-	// setup() will ignore any middlewares added after first compile if not careful,
-	// but standard app lifecycle is: New -> Use... -> Run.
-	// We just rely on Dispatch compiled in setup().
-
-	srv := &http.Server{
-		Addr:    address,
-		Handler: a,
-	}
-
-	// Channel to listen for errors coming from the listener.
-	serverErrors := make(chan error, 1)
-
-	go func() {
-		a.setup() // Ensure middlewares are compiled before starting
-		log.Printf("Server is starting on %s...", address)
-		if certFile != "" && keyFile != "" {
-			serverErrors <- srv.ListenAndServeTLS(certFile, keyFile)
-		} else {
-			serverErrors <- srv.ListenAndServe()
-		}
-	}()
-
-	// Buffered channel to receive OS signals.
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
-
-	// Block until a signal is received or an error occurs
-	select {
-	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
-
-	case sig := <-shutdown:
-		log.Printf("shutdown started: signal %v", sig)
-
-		// Create a context with a timeout for the shutdown process.
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		// Ask the server to shut down gracefully.
-		if err := srv.Shutdown(ctx); err != nil {
-			// Force close if graceful shutdown fails
-			srv.Close()
-			return fmt.Errorf("could not stop server gracefully: %w", err)
-		}
-	}
-
-	return nil
+	return a.listen(address, certFile, keyFile)
 }
 
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -255,12 +349,15 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := a.pool.Get().(*Context)
-	ctx.Reset(w, r)
+	ctx.Reset(w, a.cleanRequest(r))
 	defer a.pool.Put(ctx)
 
 	if err := a.handler(ctx); err != nil {
-		a.errorHandler(ctx, err, http.StatusInternalServerError)
-		return
+		a.handleError(ctx, err, http.StatusInternalServerError)
+	}
+	// net/http sends the implicit 200 itself, so write it here to run the Before hooks
+	if rw := ctx.Response(); !rw.Written() {
+		rw.WriteHeader(http.StatusOK)
 	}
 }
 
@@ -269,20 +366,92 @@ func (a *App) setup() {
 		// Compile the global middlewares with the router handler (dispatch)
 		// This ensures that global middlewares run even if the route is not found
 		a.handler = Compile(a.dispatch, a.middlewares...)
+		if cr, ok := a.router.(ConfigurableRouter); ok {
+			a.cleanPaths = cr.Config().CleanPath == PathLenient
+		}
 	})
 }
 
+// cleanRequest returns a copy of r with a clean path if the router serves unclean paths
+// leniently, so that middlewares checking path prefixes see the path that is routed.
+func (a *App) cleanRequest(r *http.Request) *http.Request {
+	if !a.cleanPaths || r == nil || r.URL == nil {
+		return r
+	}
+	p := r.URL.Path
+	cleaned := "/"
+	if p != "" {
+		cleaned = path.Clean(p)
+		if p[0] != '/' {
+			cleaned = path.Clean("/" + p)
+		}
+		if cleaned != "/" && strings.HasSuffix(p, "/") {
+			cleaned += "/"
+		}
+	}
+	if cleaned == p {
+		return r
+	}
+	r = r.WithContext(r.Context())
+	u := *r.URL
+	u.Path, u.RawPath = cleaned, ""
+	r.URL = &u
+	return r
+}
+
 func (a *App) dispatch(c *Context) error {
 	// Pass ctx to Find so it can populate params without allocation
 	route, err := a.router.Find(c.Request.Method, c.Request.URL.Path, c)
 	if err != nil {
-		a.errorHandler(c, err, http.StatusNotFound)
+		var mna *MethodNotAllowedError
+		if errors.As(err, &mna) {
+			if mna = a.listenerMethods(c, mna); mna == nil {
+				a.handleError(c, NewHTTPError(http.StatusNotFound, "route not found"), http.StatusNotFound)
+				return nil
+			}
+			c.Writer.Header().Set("Allow", mna.AllowHeader())
+			// Answer OPTIONS automatically when no explicit OPTIONS route exists
+			if c.Request.Method == http.MethodOptions {
+				c.Writer.WriteHeader(http.StatusNoContent)
+				return nil
+			}
+			a.handleError(c, mna, http.StatusMethodNotAllowed)
+			return nil
+		}
+		a.handleError(c, err, http.StatusNotFound)
 		return nil
 	}
+	if len(route.Listeners) > 0 && !slices.Contains(route.Listeners, c.Listener()) {
+		a.handleError(c, NewHTTPError(http.StatusNotFound, "route not found"), http.StatusNotFound)
+		return nil
+	}
+	c.route = route
 	// route.Middlewares are already compiled into route.Handler
 	return route.Handler(c)
 }
 
+// listenerMethods narrows mna to the methods whose route for the request path is served
+// by the listener of c, so that routes of other listeners are not advertised.
+// It returns nil if none are left.
+func (a *App) listenerMethods(c *Context, mna *MethodNotAllowedError) *MethodNotAllowedError {
+	mark := len(c.Params)
+	allowed := make([]string, 0, len(mna.Allowed))
+	for _, method := range mna.Allowed {
+		route, err := a.router.Find(method, c.Request.URL.Path, c)
+		if err == nil && (len(route.Listeners) == 0 || slices.Contains(route.Listeners, c.Listener())) {
+			allowed = append(allowed, method)
+		}
+		c.Params = c.Params[:mark]
+	}
+	switch len(allowed) {
+	case 0:
+		return nil
+	case len(mna.Allowed):
+		return mna
+	}
+	return &MethodNotAllowedError{Allowed: allowed}
+}
+
 func Chain(middlewares ...Middleware) Middleware {
 	return func(next Handler) Handler {
 		for i := len(middlewares) - 1; i >= 0; i-- {
@@ -0,0 +1,139 @@
+package amaro
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraint reports whether a path segment is a valid value for a parameter.
+type Constraint func(value string) bool
+
+// ConstraintParser splits a parameter, as returned by a ParamParser, into its name
+// and constraint expression. The expression is empty for unconstrained parameters.
+type ConstraintParser func(param string) (name, expr string)
+
+// DefaultConstraintParser implements the id<int> and id:[0-9]+ syntax,
+// which gives :id<int>, {id:int} and {id:[0-9]+} with the DefaultParamParser.
+func DefaultConstraintParser(param string) (string, string) {
+	if len(param) > 0 && param[len(param)-1] == '>' {
+		if i := strings.IndexByte(param, '<'); i > 0 {
+			return param[:i], param[i+1 : len(param)-1]
+		}
+	}
+	if i := strings.IndexByte(param, ':'); i > 0 {
+		return param[:i], param[i+1:]
+	}
+	return param, ""
+}
+
+// DefaultConstraints returns the built-in named constraints:
+// int, uint, float, alpha, alnum and uuid.
+func DefaultConstraints() map[string]Constraint {
+	return map[string]Constraint{
+		"int":   isInt,
+		"uint":  isUint,
+		"float": isFloat,
+		"alpha": isAlpha,
+		"alnum": isAlnum,
+		"uuid":  isUUID,
+	}
+}
+
+// Constraint compiles a constraint expression. Named constraints from
+// config.Constraints take precedence; anything else is treated as a regular
+// expression that must match the whole segment.
+func (config RouterConfig) Constraint(expr string) (Constraint, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if c, ok := config.Constraints[expr]; ok {
+		return c, nil
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid param constraint %q: %w", expr, err)
+	}
+	return re.MatchString, nil
+}
+
+func isUint(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isInt(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	return isUint(s)
+}
+
+func isFloat(s string) bool {
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		if i == 0 {
+			return isUint(s[1:])
+		}
+		if i == len(s)-1 {
+			return isUint(s[:i])
+		}
+		return isUint(s[:i]) && isUint(s[i+1:])
+	}
+	return isUint(s)
+}
+
+func isAlpha(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20 // lower-case ASCII letters
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && ((c|0x20) < 'a' || (c|0x20) > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && ((c|0x20) < 'a' || (c|0x20) > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -3,7 +3,6 @@ package amaro
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -11,7 +10,6 @@ import (
 	"path/filepath"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
 // FormFile returns the first file for the provided form key.
@@ -56,6 +54,10 @@ type Context struct {
 	Writer  http.ResponseWriter
 	Params  []Param // efficient slice instead of map
 	Keys    map[string]interface{}
+
+	app      *App
+	route    *Route // matched route, set by the App after routing
+	response ResponseWriter
 }
 
 type ContextOption func(*Context)
@@ -63,7 +65,8 @@ type ContextOption func(*Context)
 // Reset resets the context to be reused in sync.Pool
 func (c *Context) Reset(w http.ResponseWriter, r *http.Request) {
 	c.Request = r
-	c.Writer = w
+	c.response.reset(w)
+	c.Writer = &c.response
 	// Resize params slice to capacity to avoid allocation if possible
 	if cap(c.Params) < 10 {
 		c.Params = make([]Param, 0, 10)
@@ -72,16 +75,18 @@ func (c *Context) Reset(w http.ResponseWriter, r *http.Request) {
 	}
 	// Reset Keys (nil them out or create new map if needed)
 	c.Keys = nil
+	c.route = nil
 }
 
 // NewContext creates a new context for the request
 func NewContext(w http.ResponseWriter, r *http.Request, options ...ContextOption) *Context {
 	ctx := &Context{
-		Request: r,
-		Writer:  w,
-		Params:  make([]Param, 0, 10),
-		Keys:    nil,
+		Request:  r,
+		Params:   make([]Param, 0, 10),
+		Keys:     nil,
+		response: ResponseWriter{Writer: w},
 	}
+	ctx.Writer = &ctx.response
 	for _, option := range options {
 		option(ctx)
 	}
@@ -137,6 +142,67 @@ func (c *Context) PathParam(name string) string {
 	return ""
 }
 
+// Route returns a read-only view of the route matched for the request,
+// and false if none matched. Global middlewares only see it after calling next,
+// since routing happens inside the chain.
+func (c *Context) Route() (RouteInfo, bool) {
+	if c.route == nil {
+		return RouteInfo{}, false
+	}
+	return RouteInfo{
+		Method: c.route.Method,
+		Path:   c.route.Path,
+		Host:   c.route.Host,
+		Name:   c.route.Name,
+		Meta:   c.route.Meta,
+	}, true
+}
+
+// RoutePath returns the registered pattern of the matched route (e.g. "/users/:id"),
+// or an empty string if none matched. Unlike the request path it has low cardinality,
+// which makes it suitable for logging, metrics and tracing.
+func (c *Context) RoutePath() string {
+	if c.route == nil {
+		return ""
+	}
+	return c.route.Path
+}
+
+// RouteMeta returns the metadata of the matched route.
+// It is empty in global middlewares before routing has happened.
+func (c *Context) RouteMeta() RouteMeta {
+	if c.route == nil {
+		return RouteMeta{}
+	}
+	return c.route.Meta
+}
+
+// Listener returns the name of the listener that accepted the request,
+// or an empty string if it was not started by Serve.
+func (c *Context) Listener() string {
+	name, _ := c.Request.Context().Value(listenerKey{}).(string)
+	return name
+}
+
+// Response returns the framework's ResponseWriter for the request, which reports the status
+// code and size of the response even when a middleware has replaced Writer.
+func (c *Context) Response() *ResponseWriter {
+	return &c.response
+}
+
+// PathParamInt returns the named path parameter parsed as an int.
+func (c *Context) PathParamInt(name string) (int, error) {
+	return strconv.Atoi(c.PathParam(name))
+}
+
+// URLFor builds the path of the named route, see App.URL.
+func (c *Context) URLFor(name string, params ...interface{}) (string, error) {
+	if c.app == nil {
+		return "", errors.New("context is not bound to an App")
+	}
+	return c.app.URL(name, params...)
+}
+
 func (c *Context) AddParam(key, value string) {
 	c.Params = append(c.Params, Param{Key: key, Value: value})
 }
@@ -189,16 +255,7 @@ func (c *Context) Get(key string) (value interface{}, exists bool) {
 
 // BindJSON binds the request body to the provided struct.
 func (c *Context) BindJSON(v interface{}) error {
-	if c.Request.Body == nil {
-		return errors.New("request body is empty")
-	}
-	if err := checkPtr(v); err != nil {
-		return err
-	}
-	if err := json.NewDecoder(c.Request.Body).Decode(v); err != nil {
-		return err
-	}
-	return validateStruct(v)
+	return c.bindBody(DecodeJSON, v)
 }
 
 // BindQuery binds the query parameters to the provided struct.
@@ -209,7 +266,7 @@ func (c *Context) BindQuery(v interface{}) error {
 	if err := bindData(v, c.Request.URL.Query(), "query"); err != nil {
 		return err
 	}
-	return validateStruct(v)
+	return ValidateStruct(v)
 }
 
 // BindForm binds the form parameters to the provided struct.
@@ -223,7 +280,7 @@ func (c *Context) BindForm(v interface{}) error {
 	if err := bindData(v, c.Request.Form, "form"); err != nil {
 		return err
 	}
-	return validateStruct(v)
+	return ValidateStruct(v)
 }
 
 func checkPtr(v interface{}) error {
@@ -331,93 +388,3 @@ func setField(val reflect.Value, inputs []string) error {
 	}
 	return nil
 }
-
-// validateStruct performs basic validation based on struct tags.
-// Supported tags: validate:"required,min=X,max=Y"
-func validateStruct(s interface{}) error {
-	// s is guaranteed to be a non-nil pointer by checkPtr
-	val := reflect.ValueOf(s).Elem()
-	typ := val.Type()
-
-	if val.Kind() != reflect.Struct {
-		return nil // validation only works on structs
-	}
-
-	var validationErrors []string
-
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		tag := typ.Field(i).Tag.Get("validate")
-		if tag == "" {
-			continue
-		}
-
-		rules := strings.Split(tag, ",")
-		for _, rule := range rules {
-			if rule == "required" {
-				if isZero(field) {
-					validationErrors = append(validationErrors, fmt.Sprintf("field '%s' is required", typ.Field(i).Name))
-				}
-			} else if strings.HasPrefix(rule, "min=") {
-				minVal, _ := strconv.Atoi(strings.TrimPrefix(rule, "min="))
-				if !checkMin(field, minVal) {
-					validationErrors = append(validationErrors, fmt.Sprintf("field '%s' must be at least %d", typ.Field(i).Name, minVal))
-				}
-			} else if strings.HasPrefix(rule, "max=") {
-				maxVal, _ := strconv.Atoi(strings.TrimPrefix(rule, "max="))
-				if !checkMax(field, maxVal) {
-					validationErrors = append(validationErrors, fmt.Sprintf("field '%s' must be at most %d", typ.Field(i).Name, maxVal))
-				}
-			}
-		}
-	}
-
-	if len(validationErrors) > 0 {
-		return errors.New(strings.Join(validationErrors, "; "))
-	}
-	return nil
-}
-
-func isZero(v reflect.Value) bool {
-	switch v.Kind() {
-	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
-		return v.Len() == 0
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return v.Int() == 0
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return v.Uint() == 0
-	case reflect.Float32, reflect.Float64:
-		return v.Float() == 0
-	case reflect.Ptr, reflect.Interface:
-		return v.IsNil()
-	}
-	return false
-}
-
-func checkMin(v reflect.Value, min int) bool {
-	switch v.Kind() {
-	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
-		return v.Len() >= min
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return v.Int() >= int64(min)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return v.Uint() >= uint64(min)
-	case reflect.Float32, reflect.Float64:
-		return v.Float() >= float64(min)
-	}
-	return true
-}
-
-func checkMax(v reflect.Value, max int) bool {
-	switch v.Kind() {
-	case reflect.String, reflect.Array, reflect.Slice, reflect.Map:
-		return v.Len() <= max
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return v.Int() <= int64(max)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return v.Uint() <= uint64(max)
-	case reflect.Float32, reflect.Float64:
-		return v.Float() <= float64(max)
-	}
-	return true
-}
@@ -1,8 +1,10 @@
 package amaro
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // HTTPError represents an error with an associated HTTP status code.
@@ -35,3 +37,58 @@ func (e *HTTPError) SetInternal(err error) *HTTPError {
 func (e *HTTPError) Unwrap() error {
 	return e.Internal
 }
+
+// MethodNotAllowedError is returned by a Router when the requested path exists
+// but is not registered for the requested method.
+// Allowed lists the methods the path is registered for.
+type MethodNotAllowedError struct {
+	Allowed []string
+}
+
+func (e *MethodNotAllowedError) Error() string {
+	return "method not allowed"
+}
+
+// AllowHeader returns the value for the Allow response header.
+// OPTIONS is always included because the framework answers it automatically.
+func (e *MethodNotAllowedError) AllowHeader() string {
+	allowed := e.Allowed
+	hasOptions := false
+	for _, m := range allowed {
+		if m == http.MethodOptions {
+			hasOptions = true
+			break
+		}
+	}
+	if !hasOptions {
+		allowed = append(allowed[:len(allowed):len(allowed)], http.MethodOptions)
+	}
+	return strings.Join(allowed, ", ")
+}
+
+// ErrRouteConflict is wrapped by the errors of registrations that clash with an existing
+// route: duplicates, param or wildcard name conflicts and routes shadowed by a wildcard.
+var ErrRouteConflict = errors.New("route conflict")
+
+// ErrRouteOptionsUnsupported is wrapped by the errors of registrations whose name,
+// metadata or listeners would be dropped because the router is not a RouteAdder.
+var ErrRouteOptionsUnsupported = errors.New("route options not supported by router")
+
+// ErrHostRoutingUnsupported is reported when App.Host is used with a router that is not a HostRouter.
+var ErrHostRoutingUnsupported = errors.New("router does not support host routing")
+
+// RouteError reports a route that could not be registered.
+type RouteError struct {
+	Method string
+	Path   string
+	Err    error
+}
+
+func (e *RouteError) Error() string {
+	return e.Method + " " + e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
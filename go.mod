@@ -7,3 +7,10 @@ require github.com/golang-jwt/jwt/v5 v5.3.0
 require golang.org/x/net v0.48.0
 
 require golang.org/x/oauth2 v0.34.0 // indirect
+
+require github.com/fxamacker/cbor/v2 v2.9.2
+
+require (
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/text v0.32.0 // indirect
+)
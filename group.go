@@ -7,10 +7,15 @@ import (
 )
 
 // Group represents a route group with a common path prefix and shared middlewares.
+// Sub-groups inherit the middlewares of their parent.
 type Group struct {
 	prefix      string
 	router      Router
+	parent      *Group
+	children    []*Group
 	middlewares []Middleware
+	listeners   []string
+	routes      []Route // routes registered through the group, without the group middlewares
 }
 
 func NewGroup(prefix string, router Router) *Group {
@@ -22,49 +27,97 @@ func NewGroup(prefix string, router Router) *Group {
 }
 
 // Use adds a middleware to the group.
-// These middlewares are applied to all routes registered in this group.
+// These middlewares are applied to all routes registered in this group and its sub-groups,
+// regardless of whether the routes were added before or after calling Use.
+// Routes added before are registered again if the router is a RouteReplacer.
 func (g *Group) Use(middleware Middleware) {
 	g.middlewares = append(g.middlewares, middleware)
+	g.reapply()
 }
 
-func (g *Group) Add(method, path string, handler Handler, middlewares ...Middleware) error {
-	var fullPath strings.Builder
-	fullPath.Grow(len(g.prefix) + len(path)) // Pre-allocate capacity
-	fullPath.WriteString(g.prefix)
-	fullPath.WriteString(path)
-	return g.router.Add(method, fullPath.String(), handler, middlewares...)
+func (g *Group) Add(method, path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(method, path, handler, options...)
+}
+
+// Handle registers a new route in the group configured by the given RouteOptions.
+func (g *Group) Handle(method, path string, handler Handler, options ...RouteOption) error {
+	route := Route{Method: method, Path: g.calculatePath(path), Listeners: g.listeners, Handler: handler}
+	for _, option := range options {
+		option.applyRoute(&route)
+	}
+
+	if err := addRoute(g.router, g.withMiddlewares(route)); err != nil {
+		return err
+	}
+	g.routes = append(g.routes, route)
+	return nil
+}
+
+// withMiddlewares returns route with the middlewares of the group prepended,
+// parent groups first, so the router compiles them into its handler.
+func (g *Group) withMiddlewares(route Route) Route {
+	chain := g.chain()
+	if len(chain) == 0 {
+		return route
+	}
+	// Group middlewares run before route-specific middlewares
+	middlewares := make([]Middleware, 0, len(chain)+len(route.Middlewares))
+	middlewares = append(middlewares, chain...)
+	route.Middlewares = append(middlewares, route.Middlewares...)
+	return route
+}
+
+// reapply registers the routes of the group and its sub-groups again with their current middlewares.
+func (g *Group) reapply() {
+	for _, route := range g.routes {
+		replaceRoute(g.router, g.withMiddlewares(route))
+	}
+	for _, child := range g.children {
+		child.reapply()
+	}
+}
+
+// chain returns the group's middlewares prefixed by those of its parents.
+func (g *Group) chain() []Middleware {
+	if g.parent == nil {
+		return g.middlewares
+	}
+	parent := g.parent.chain()
+	chain := make([]Middleware, 0, len(parent)+len(g.middlewares))
+	chain = append(chain, parent...)
+	return append(chain, g.middlewares...)
 }
 
-func (g *Group) GET(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodGet, path, handler, middlewares...)
+func (g *Group) GET(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodGet, path, handler, options...)
 }
 
-func (g *Group) POST(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodPost, path, handler, middlewares...)
+func (g *Group) POST(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodPost, path, handler, options...)
 }
 
-func (g *Group) PUT(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodPut, path, handler, middlewares...)
+func (g *Group) PUT(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodPut, path, handler, options...)
 }
 
-func (g *Group) DELETE(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodDelete, path, handler, middlewares...)
+func (g *Group) DELETE(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodDelete, path, handler, options...)
 }
 
-func (g *Group) PATCH(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodPatch, path, handler, middlewares...)
+func (g *Group) PATCH(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodPatch, path, handler, options...)
 }
 
-func (g *Group) OPTIONS(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodOptions, path, handler, middlewares...)
+func (g *Group) OPTIONS(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodOptions, path, handler, options...)
 }
 
-func (g *Group) HEAD(path string, handler Handler, middlewares ...Middleware) error {
-	return g.Add(http.MethodHead, path, handler, middlewares...)
+func (g *Group) HEAD(path string, handler Handler, options ...RouteOption) error {
+	return g.Handle(http.MethodHead, path, handler, options...)
 }
 
 // Any registers a route that matches all standard HTTP methods.
-func (g *Group) Any(path string, handler Handler, middlewares ...Middleware) error {
+func (g *Group) Any(path string, handler Handler, options ...RouteOption) error {
 	methods := []string{
 		http.MethodGet,
 		http.MethodPost,
@@ -75,7 +128,7 @@ func (g *Group) Any(path string, handler Handler, middlewares ...Middleware) err
 		http.MethodHead,
 	}
 	for _, method := range methods {
-		if err := g.Add(method, path, handler, middlewares...); err != nil {
+		if err := g.Handle(method, path, handler, options...); err != nil {
 			return err
 		}
 	}
@@ -84,8 +137,9 @@ func (g *Group) Any(path string, handler Handler, middlewares ...Middleware) err
 
 // Mount registers an http.Handler (e.g., grpc-gateway mux) at the specified path prefix within the group.
 // It registers the handler for all standard HTTP methods for the exact path and all subpaths.
-func (g *Group) Mount(path string, handler http.Handler) error {
-	h := WrapHTTPHandler(handler)
+// With WithStripPrefix the handler sees paths relative to the group prefix and path.
+func (g *Group) Mount(path string, handler http.Handler, options ...MountOption) error {
+	h := mountHandler(g.calculatePath(path), handler, options)
 
 	// Exact match
 	if err := g.Any(path, h); err != nil {
@@ -102,16 +156,42 @@ func (g *Group) Mount(path string, handler http.Handler) error {
 	return g.Any(wildcardPath, h)
 }
 
+// Group creates a sub-group that inherits the prefix and middlewares of g.
 func (g *Group) Group(prefix string) *Group {
-	return NewGroup(g.prefix+prefix, g.router)
+	child := NewGroup(g.prefix+prefix, g.router)
+	child.parent = g
+	child.listeners = g.listeners
+	g.children = append(g.children, child)
+	return child
+}
+
+// ListenerGroup returns a sub-group with the same prefix whose routes are only served
+// by the named listeners started by Serve.
+func (g *Group) ListenerGroup(names ...string) *Group {
+	child := g.Group("")
+	child.listeners = names
+	return child
 }
 
 func (g *Group) Find(method, path string) (*Route, error) {
 	return g.router.Find(method, g.calculatePath(path), nil)
 }
 
-func (g *Group) StaticFS(pathPrefix string, fs fs.FS) {
-	g.router.StaticFS(g.calculatePath(pathPrefix), fs)
+// StaticFS serves files from fsys under the group prefix.
+// Routes are registered through the group so its middlewares apply.
+func (g *Group) StaticFS(pathPrefix string, fsys fs.FS) {
+	handler := StaticHandler(StaticConfig{
+		Root:   fsys,
+		Prefix: g.calculatePath(pathPrefix),
+	})
+
+	path := strings.TrimRight(pathPrefix, "/")
+	g.GET(path, handler)
+	g.HEAD(path, handler)
+
+	wildcardPath := path + "/*filepath"
+	g.GET(wildcardPath, handler)
+	g.HEAD(wildcardPath, handler)
 }
 
 func (g *Group) calculatePath(path string) string {
@@ -0,0 +1,126 @@
+package amaro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+// trace returns a middleware that appends name to the X-Trace response header.
+func trace(name string) amaro.Middleware {
+	return func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			c.Writer.Header().Add("X-Trace", name)
+			return next(c)
+		}
+	}
+}
+
+func TestGroupMiddlewares(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+
+	api := app.Group("/api")
+	api.Use(trace("api"))
+
+	// Registered before the sub-group's Use call
+	v1 := api.Group("/v1")
+	v1.GET("/users", handler, trace("route"))
+	v1.Use(trace("v1"))
+
+	// Registered before the parent's second Use call
+	api.GET("/status", handler)
+	api.Use(trace("api2"))
+
+	app.GET("/public", handler)
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/users", "api,api2,v1,route"},
+		{"/api/status", "api,api2"},
+		{"/public", ""},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		w := app.Test(req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", tc.path, w.Code)
+		}
+		if got := strings.Join(w.Header().Values("X-Trace"), ","); got != tc.want {
+			t.Errorf("%s: expected trace %q, got %q", tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestGroupMiddlewares_Blocking(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	admin := app.Group("/admin")
+	admin.GET("/secret", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "secret")
+	})
+	admin.StaticFS("/files", fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("file")},
+	})
+	admin.Use(func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			return amaro.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+		}
+	})
+
+	for _, path := range []string{"/admin/secret", "/admin/files/a.txt"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := app.Test(req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestGroupMiddlewares_UseAfterServing(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+
+	api := app.Group("/api")
+	api.GET("/users", handler)
+	app.Group("/public").GET("/status", handler)
+
+	// Groups without middlewares leave the route untouched
+	for _, route := range app.Routes() {
+		if len(route.Middlewares) != 0 {
+			t.Errorf("%s: expected no middlewares, got %d", route.Path, len(route.Middlewares))
+		}
+	}
+
+	if w := app.Test(httptest.NewRequest(http.MethodGet, "/api/users", nil)); w.Header().Get("X-Trace") != "" {
+		t.Fatalf("Expected no trace, got %q", w.Header().Get("X-Trace"))
+	}
+
+	api.Use(trace("api"))
+
+	w := app.Test(httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	if got := w.Header().Get("X-Trace"); got != "api" {
+		t.Errorf("Expected trace %q after Use, got %q", "api", got)
+	}
+	for _, route := range app.Routes() {
+		want := 0
+		if route.Path == "/api/users" {
+			want = 1
+		}
+		if len(route.Middlewares) != want {
+			t.Errorf("%s: expected %d middlewares, got %d", route.Path, want, len(route.Middlewares))
+		}
+	}
+}
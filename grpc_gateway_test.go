@@ -22,8 +22,8 @@ func TestMount(t *testing.T) {
 	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
 
 	// Mount the mux at /gateway
-	// Use StripPrefix so mux sees paths starting from /foo, /bar/baz
-	app.Mount("/gateway", http.StripPrefix("/gateway", mux))
+	// Strip the prefix so mux sees paths starting from /foo, /bar/baz
+	app.Mount("/gateway", mux, amaro.WithStripPrefix())
 
 	t.Run("Exact Match", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/gateway/foo", nil)
@@ -61,7 +61,7 @@ func TestGroupMount(t *testing.T) {
 
 	// Mount at /api -> so it handles /api/v1/echo
 	// Strip /api so mux sees /v1/echo
-	api.Mount("/", http.StripPrefix("/api", mux))
+	api.Mount("/", mux, amaro.WithStripPrefix())
 
 	req := httptest.NewRequest("GET", "/api/v1/echo", nil)
 	w := app.Test(req)
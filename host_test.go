@@ -0,0 +1,124 @@
+package amaro_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestHostRouting(t *testing.T) {
+	newRouters := map[string]func() amaro.Router{
+		"Trie":     func() amaro.Router { return routers.NewTrieRouter() },
+		"Radix":    func() amaro.Router { return routers.NewRadixRouter() },
+		"ServeMux": func() amaro.Router { return routers.NewServeMuxRouter() },
+	}
+
+	for name, newRouter := range newRouters {
+		t.Run(name, func(t *testing.T) {
+			app := amaro.New(amaro.WithRouter(newRouter()))
+
+			app.GET("/", func(c *amaro.Context) error {
+				return c.String(http.StatusOK, "default")
+			})
+
+			api := app.Host("api.example.com")
+			api.GET("/users/:id", func(c *amaro.Context) error {
+				return c.String(http.StatusOK, "api user "+c.PathParam("id"))
+			})
+
+			tenant := app.Host("{tenant}.example.com")
+			tenant.GET("/", func(c *amaro.Context) error {
+				return c.String(http.StatusOK, "tenant "+c.PathParam("tenant"))
+			})
+			tenant.Group("/admin").GET("/:section", func(c *amaro.Context) error {
+				return c.String(http.StatusOK, c.PathParam("tenant")+" admin "+c.PathParam("section"))
+			})
+
+			app.Host("*.cdn.example.com").GET("/*filepath", func(c *amaro.Context) error {
+				return c.String(http.StatusOK, "cdn "+c.PathParam("filepath"))
+			})
+
+			cases := []struct {
+				host string
+				path string
+				code int
+				body string
+			}{
+				{"example.com", "/", http.StatusOK, "default"},
+				{"localhost:8080", "/", http.StatusOK, "default"},
+				// Static hosts win over params
+				{"api.example.com", "/users/7", http.StatusOK, "api user 7"},
+				{"API.example.com:443", "/users/7", http.StatusOK, "api user 7"},
+				{"acme.example.com", "/", http.StatusOK, "tenant acme"},
+				{"acme.example.com", "/admin/billing", http.StatusOK, "acme admin billing"},
+				{"eu.assets.cdn.example.com", "/img/logo.png", http.StatusOK, "cdn img/logo.png"},
+				// Host routes are exclusive
+				{"api.example.com", "/", http.StatusNotFound, ""},
+				{"a.b.example.com", "/admin/billing", http.StatusNotFound, ""},
+			}
+			for _, tc := range cases {
+				req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+				req.Host = tc.host
+				w := app.Test(req)
+				if w.Code != tc.code {
+					t.Errorf("%s%s: expected %d, got %d", tc.host, tc.path, tc.code, w.Code)
+				}
+				if tc.body != "" && w.Body.String() != tc.body {
+					t.Errorf("%s%s: expected %q, got %q", tc.host, tc.path, tc.body, w.Body.String())
+				}
+			}
+
+			hosts := make(map[string]int)
+			for _, route := range app.Routes() {
+				hosts[route.Host]++
+			}
+			if hosts[""] != 1 || hosts["api.example.com"] != 1 || hosts["{tenant}.example.com"] != 2 {
+				t.Errorf("Unexpected route hosts %v", hosts)
+			}
+		})
+	}
+}
+
+func TestHostRouting_RouterMiddlewares(t *testing.T) {
+	router := routers.NewTrieRouter()
+	app := amaro.New(amaro.WithRouter(router))
+
+	api := app.Host("api.example.com")
+	router.Use(trace("router"))
+	api.GET("/", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "api")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "api.example.com"
+	w := app.Test(req)
+	if w.Code != http.StatusOK || w.Header().Get("X-Trace") != "router" {
+		t.Errorf("Expected 200 with router middleware, got %d %q", w.Code, w.Header().Get("X-Trace"))
+	}
+}
+
+func TestHostRouting_Errors(t *testing.T) {
+	handler := func(c *amaro.Context) error { return nil }
+
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.Host("api..example.com").GET("/", handler)
+	if err := app.Validate(); err == nil || !strings.Contains(err.Error(), "api..example.com") {
+		t.Errorf("Expected Validate to report the invalid host pattern, got %v", err)
+	}
+
+	app = amaro.New(amaro.WithRouter(plainRouter{routers.NewTrieRouter()}))
+	if err := app.Host("api.example.com").GET("/", handler); !errors.Is(err, amaro.ErrHostRoutingUnsupported) {
+		t.Errorf("Expected ErrHostRoutingUnsupported from the registration, got %v", err)
+	}
+	if err := app.Validate(); !errors.Is(err, amaro.ErrHostRoutingUnsupported) {
+		t.Errorf("Expected Validate to report ErrHostRoutingUnsupported, got %v", err)
+	}
+	if w := app.Test(httptest.NewRequest(http.MethodGet, "/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected the host route not to be served, got %d", w.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package amaro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestMethodNotAllowed(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	app.GET("/items/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "get")
+	})
+	app.DELETE("/items/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "delete")
+	})
+	app.OPTIONS("/custom", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "custom options")
+	})
+	app.GET("/custom", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "custom")
+	})
+
+	t.Run("405 with Allow", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items/1", nil)
+		w := app.Test(req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected 405, got %d", w.Code)
+		}
+		if got := w.Header().Get("Allow"); got != "DELETE, GET, OPTIONS" {
+			t.Errorf("Expected Allow 'DELETE, GET, OPTIONS', got '%s'", got)
+		}
+	})
+
+	t.Run("Automatic OPTIONS", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/items/1", nil)
+		w := app.Test(req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", w.Code)
+		}
+		if got := w.Header().Get("Allow"); got != "DELETE, GET, OPTIONS" {
+			t.Errorf("Expected Allow 'DELETE, GET, OPTIONS', got '%s'", got)
+		}
+	})
+
+	t.Run("Explicit OPTIONS", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/custom", nil)
+		w := app.Test(req)
+
+		if w.Body.String() != "custom options" {
+			t.Errorf("Expected explicit OPTIONS handler, got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("Unknown path is 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/nothing", nil)
+		w := app.Test(req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", w.Code)
+		}
+	})
+}
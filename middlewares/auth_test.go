@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/buildwithgo/amaro"
@@ -108,3 +109,65 @@ func (m *mockWriter) Write(b []byte) (int, error) {
 func (m *mockWriter) WriteHeader(statusCode int) {
 	m.code = statusCode
 }
+
+func TestScopes(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	extractor := func(c *amaro.Context) ([]string, error) {
+		return strings.Fields(c.GetHeader("X-Scopes")), nil
+	}
+
+	api := app.Group("/api")
+	api.Use(Scopes(extractor))
+	api.Handle(http.MethodDelete, "/users/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "Deleted")
+	}, amaro.WithScopes("users:write", "users:delete"))
+	api.GET("/users", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "Users")
+	})
+
+	// Case 1: Missing scope
+	req, _ := http.NewRequest("DELETE", "/api/users/1", nil)
+	req.Header.Set("X-Scopes", "users:write")
+	w := &mockWriter{}
+	app.ServeHTTP(w, req)
+	if w.code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d", w.code)
+	}
+
+	// Case 2: All scopes granted
+	req, _ = http.NewRequest("DELETE", "/api/users/1", nil)
+	req.Header.Set("X-Scopes", "users:write users:delete")
+	w = &mockWriter{}
+	app.ServeHTTP(w, req)
+	if w.code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.code)
+	}
+
+	// Case 3: Route without scopes
+	req, _ = http.NewRequest("GET", "/api/users", nil)
+	w = &mockWriter{}
+	app.ServeHTTP(w, req)
+	if w.code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.code)
+	}
+}
+
+func TestScopes_GlobalFailsClosed(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.Use(Scopes(func(c *amaro.Context) ([]string, error) {
+		return strings.Fields(c.GetHeader("X-Scopes")), nil
+	}))
+	app.Handle(http.MethodGet, "/admin", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "secret")
+	}, amaro.WithScopes("admin"))
+
+	for _, path := range []string{"/admin", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := &mockWriter{}
+		app.ServeHTTP(w, req)
+		if w.code != http.StatusInternalServerError || w.body == "secret" {
+			t.Errorf("%s: Expected 500 without the handler running, got %d %q", path, w.code, w.body)
+		}
+	}
+}
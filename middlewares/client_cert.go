@@ -0,0 +1,114 @@
+package middlewares
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/buildwithgo/amaro"
+)
+
+const ClientCertKey = "client_cert"
+
+// ClientCert describes the verified certificate a client presented over mutual TLS.
+type ClientCert struct {
+	// Subject is the distinguished name of the certificate, e.g. Subject.CommonName.
+	Subject pkix.Name
+
+	// DNSNames, EmailAddresses, IPAddresses and URIs are the subject alternative names,
+	// e.g. a SPIFFE ID in URIs.
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// Certificate is the verified leaf certificate.
+	Certificate *x509.Certificate
+}
+
+// ClientCertConfig holds the configuration for the ClientCert middleware.
+type ClientCertConfig struct {
+	// Validator authorizes the client certificate. Requests are forbidden if it returns false.
+	// All verified certificates are accepted if nil.
+	Validator func(cert *ClientCert, c *amaro.Context) (bool, error)
+
+	// Optional lets requests without a verified client certificate through,
+	// e.g. with tls.VerifyClientCertIfGiven. They are rejected with 401 otherwise.
+	Optional bool
+
+	// Skipper defines a function to skip middleware.
+	Skipper func(c *amaro.Context) bool
+}
+
+// DefaultClientCertConfig returns a default configuration.
+func DefaultClientCertConfig() ClientCertConfig {
+	return ClientCertConfig{
+		Skipper: func(c *amaro.Context) bool { return false },
+	}
+}
+
+// ClientCertAuth returns a middleware that stores the verified client certificate of a
+// mutual TLS connection in the context, see GetClientCert. Client certificates must be
+// verified by the server, e.g. with ServerConfig.ClientCAFile.
+func ClientCertAuth(validator func(cert *ClientCert, c *amaro.Context) (bool, error)) amaro.Middleware {
+	config := DefaultClientCertConfig()
+	config.Validator = validator
+	return ClientCertAuthWithConfig(config)
+}
+
+// ClientCertAuthWithConfig returns a ClientCertAuth middleware with custom configuration.
+func ClientCertAuthWithConfig(config ClientCertConfig) amaro.Middleware {
+	if config.Skipper == nil {
+		config.Skipper = DefaultClientCertConfig().Skipper
+	}
+
+	return func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			state := c.Request.TLS
+			// Only verified chains count; certificates presented with
+			// tls.RequestClientCert are not trustworthy
+			if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+				if config.Optional {
+					return next(c)
+				}
+				return amaro.NewHTTPError(http.StatusUnauthorized, "Client certificate required")
+			}
+
+			leaf := state.VerifiedChains[0][0]
+			cert := &ClientCert{
+				Subject:        leaf.Subject,
+				DNSNames:       leaf.DNSNames,
+				EmailAddresses: leaf.EmailAddresses,
+				IPAddresses:    leaf.IPAddresses,
+				URIs:           leaf.URIs,
+				Certificate:    leaf,
+			}
+
+			if config.Validator != nil {
+				valid, err := config.Validator(cert, c)
+				if err != nil {
+					return err
+				}
+				if !valid {
+					return amaro.NewHTTPError(http.StatusForbidden, "Forbidden")
+				}
+			}
+
+			c.Set(ClientCertKey, cert)
+			return next(c)
+		}
+	}
+}
+
+// GetClientCert returns the client certificate stored by ClientCertAuth, or nil if there is none.
+func GetClientCert(c *amaro.Context) *ClientCert {
+	cert, _ := c.Get(ClientCertKey)
+	clientCert, _ := cert.(*ClientCert)
+	return clientCert
+}
@@ -27,9 +27,12 @@ func (w *gzipResponseWriter) Flush() {
 	if f, ok := w.Writer.(*gzip.Writer); ok {
 		f.Flush()
 	}
-	if f, ok := w.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to hijack the connection.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 // Compress returns a middleware that compresses HTTP responses using Gzip.
@@ -44,19 +47,18 @@ func Compress() amaro.Middleware {
 			c.Writer.Header().Set("Content-Encoding", "gzip")
 			c.Writer.Header().Set("Vary", "Accept-Encoding")
 
-			gz := gzip.NewWriter(c.Writer)
+			rw := c.Response()
+			originalWriter := rw.Writer
+			gz := gzip.NewWriter(originalWriter)
 			defer gz.Close()
 
-			gzw := &gzipResponseWriter{Writer: gz, ResponseWriter: c.Writer}
-
-			// Temporarily replace writer
-			originalWriter := c.Writer
-			c.Writer = gzw
+			// Temporarily compress everything written through the Context's ResponseWriter
+			rw.Writer = &gzipResponseWriter{Writer: gz, ResponseWriter: originalWriter}
 
 			err := next(c)
 
 			// Restore
-			c.Writer = originalWriter
+			rw.Writer = originalWriter
 			return err
 		}
 	}
@@ -102,32 +102,11 @@ func Logger(opts ...LoggerOption) amaro.Middleware {
 	return func(next amaro.Handler) amaro.Handler {
 		return func(c *amaro.Context) error {
 			start := time.Now()
-			// Default status code to 200.
-			lrw := &loggingResponseWriter{ResponseWriter: c.Writer, statusCode: http.StatusOK}
-			c.Writer = lrw
-
 			err := next(c)
 			duration := time.Since(start)
 
-			cfg.printFunc(cfg.logger, duration, c, lrw.statusCode)
+			cfg.printFunc(cfg.logger, duration, c, c.Response().Status())
 			return err
 		}
 	}
 }
-
-type loggingResponseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-func (lrw *loggingResponseWriter) WriteHeader(code int) {
-	lrw.statusCode = code
-	lrw.ResponseWriter.WriteHeader(code)
-}
-
-// Flush implements the http.Flusher interface to allow streaming.
-func (lrw *loggingResponseWriter) Flush() {
-	if flusher, ok := lrw.ResponseWriter.(http.Flusher); ok {
-		flusher.Flush()
-	}
-}
@@ -1,7 +1,9 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/buildwithgo/amaro"
 )
@@ -60,3 +62,38 @@ func ACL(allowedRoles []string, roleExtractor func(c *amaro.Context) (string, er
 		}
 	}
 }
+
+// errScopesWithoutRoute is returned by Scopes when it runs before routing.
+var errScopesWithoutRoute = errors.New("middlewares: Scopes must be registered as a route or group middleware")
+
+// Scopes enforces the scopes declared on the matched route with amaro.WithScopes.
+// The request must hold every declared scope. Routes without scopes are allowed.
+// It must be registered as a route or group middleware so the route is known. Registered
+// with App.Use it runs before routing, so it fails every request instead of letting it through.
+func Scopes(scopesExtractor func(c *amaro.Context) ([]string, error)) amaro.Middleware {
+	return func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			route, ok := c.Route()
+			if !ok {
+				return errScopesWithoutRoute
+			}
+			required := route.Meta.Scopes
+			if len(required) == 0 {
+				return next(c)
+			}
+
+			granted, err := scopesExtractor(c)
+			if err != nil {
+				return amaro.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			for _, scope := range required {
+				if !slices.Contains(granted, scope) {
+					return amaro.NewHTTPError(http.StatusForbidden, "Forbidden")
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -7,7 +7,8 @@ import (
 	"github.com/buildwithgo/amaro"
 )
 
-const RequestIDKey = "request_id"
+// RequestIDKey is the context key the request ID is stored under.
+const RequestIDKey = amaro.RequestIDKey
 
 // RequestID adds an X-Request-ID header to the response and context.
 func RequestID() amaro.Middleware {
@@ -0,0 +1,215 @@
+package amaro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MountOption configures how Mount hands requests to the mounted handler.
+type MountOption func(*mountConfig)
+
+type mountConfig struct {
+	stripPrefix bool
+}
+
+// WithStripPrefix makes Mount rewrite URL.Path and URL.RawPath relative to the mount point,
+// so the handler sees "/v1/echo" for "/api/v1/echo" mounted at "/api". Params in the mount
+// path and group prefixes are stripped along with it. The original path is available
+// through OriginalPath and the X-Forwarded-Prefix header is set to the stripped prefix,
+// replacing the one sent by the client. Prefixes stripped by nested mounts are joined.
+//
+//	app.Mount("/debug/pprof", pprofMux, amaro.WithStripPrefix())
+func WithStripPrefix() MountOption {
+	return func(config *mountConfig) {
+		config.stripPrefix = true
+	}
+}
+
+type originalPathKey struct{}
+
+// strippedPrefixKey holds the prefixes stripped by mount points so far.
+type strippedPrefixKey struct{}
+
+// OriginalPath returns the request path before a mount point stripped its prefix,
+// or URL.Path if no prefix was stripped.
+func OriginalPath(r *http.Request) string {
+	if p, ok := r.Context().Value(originalPathKey{}).(string); ok {
+		return p
+	}
+	return r.URL.Path
+}
+
+// mountHandler wraps handler mounted at prefix according to options.
+func mountHandler(prefix string, handler http.Handler, options []MountOption) Handler {
+	var config mountConfig
+	for _, option := range options {
+		option(&config)
+	}
+	if !config.stripPrefix {
+		return WrapHTTPHandler(handler)
+	}
+
+	// Params match a single segment, so the prefix length is counted in segments
+	segments := 0
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment != "" {
+			segments++
+		}
+	}
+
+	return func(c *Context) error {
+		handler.ServeHTTP(c.Writer, stripPrefix(c.Request, segments))
+		return nil
+	}
+}
+
+// stripPrefix returns a shallow copy of r with the first n segments of its path removed.
+func stripPrefix(r *http.Request, n int) *http.Request {
+	i := skipSegments(r.URL.Path, n)
+
+	ctx := r.Context()
+	if _, ok := ctx.Value(originalPathKey{}).(string); !ok {
+		ctx = context.WithValue(ctx, originalPathKey{}, r.URL.Path)
+	}
+	prefix, _ := ctx.Value(strippedPrefixKey{}).(string)
+	prefix += r.URL.Path[:i]
+	ctx = context.WithValue(ctx, strippedPrefixKey{}, prefix)
+	r2 := r.WithContext(ctx)
+
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = rooted(r.URL.Path[i:])
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = rooted(r.URL.RawPath[skipSegments(r.URL.RawPath, n):])
+	}
+
+	r2.Header = r.Header.Clone()
+	if r2.Header == nil {
+		r2.Header = make(http.Header)
+	}
+	r2.Header.Set("X-Forwarded-Prefix", prefix)
+	return r2
+}
+
+// skipSegments returns the index in p following its first n non-empty segments.
+func skipSegments(p string, n int) int {
+	i := 0
+	for ; n > 0; n-- {
+		for i < len(p) && p[i] == '/' {
+			i++
+		}
+		for i < len(p) && p[i] != '/' {
+			i++
+		}
+	}
+	return i
+}
+
+func rooted(p string) string {
+	if p == "" || p[0] != '/' {
+		return "/" + p
+	}
+	return p
+}
+
+// Route mounts the routes of child under prefix, like Hono's app.route().
+// The routes are merged into the routing table of a, keeping their names and metadata,
+// and run inside the global middlewares and error handler of child, so feature modules
+// can be built and tested as independent Apps. Path params of prefix and of the child
+// routes are both available. Routes registered on child after mounting are not included.
+//
+//	users := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+//	users.GET("/:id", showUser)
+//	app.Route("/orgs/:org/users", users) // GET /orgs/:org/users/:id
+func (a *App) Route(prefix string, child *App) error {
+	var errs []error
+	for _, route := range child.mountedRoutes(prefix) {
+		if err := addRoute(a.router, route); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Route mounts the routes of child under prefix within the group.
+// The group middlewares run before those of child. See App.Route.
+func (g *Group) Route(prefix string, child *App) error {
+	var errs []error
+	for _, route := range child.mountedRoutes(g.calculatePath(prefix)) {
+		if err := addRoute(g.router, g.withMiddlewares(route)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		g.routes = append(g.routes, route)
+	}
+	return errors.Join(errs...)
+}
+
+// addRoute registers route on router, or on the router of its host.
+func addRoute(router Router, route Route) error {
+	if route.Host == "" {
+		return registerRoute(router, route)
+	}
+	hr, ok := router.(HostRouter)
+	if !ok {
+		return &RouteError{Method: route.Method, Path: route.Path, Err: ErrHostRoutingUnsupported}
+	}
+	return registerRoute(hr.Host(route.Host).router, route)
+}
+
+// replaceRoute registers route again on router, or on the router of its host,
+// if that router is a RouteReplacer.
+func replaceRoute(router Router, route Route) error {
+	if route.Host != "" {
+		if hr, ok := router.(HostRouter); ok {
+			router = hr.Host(route.Host).router
+		}
+	}
+	rr, ok := router.(RouteReplacer)
+	if !ok {
+		return fmt.Errorf("%s %s: router does not support replacing routes", route.Method, route.Path)
+	}
+	return rr.ReplaceRoute(route)
+}
+
+// mountedRoutes returns the routes of a prefixed with prefix.
+// Their handlers are wrapped in the global middlewares and error handler of a.
+func (a *App) mountedRoutes(prefix string) []Route {
+	prefix = strings.TrimRight(prefix, "/")
+	wrap := a.mountMiddleware()
+
+	routes := a.Routes()
+	for i := range routes {
+		route := &routes[i]
+		if route.Path != "/" {
+			route.Path = prefix + route.Path
+		} else if prefix != "" {
+			route.Path = prefix
+		}
+		// Middlewares are already compiled into the handler
+		route.Handler = wrap(route.Handler)
+		route.Middlewares = nil
+	}
+	return routes
+}
+
+// mountMiddleware applies the global middlewares of a and, if set, its error handler.
+func (a *App) mountMiddleware() Middleware {
+	return func(next Handler) Handler {
+		handler := Compile(next, a.middlewares...)
+		if a.errorHandler == nil {
+			// Errors are left to the parent's error handler
+			return handler
+		}
+		return func(c *Context) error {
+			if err := handler(c); err != nil {
+				a.errorHandler(c, err, http.StatusInternalServerError)
+			}
+			return nil
+		}
+	}
+}
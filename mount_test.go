@@ -0,0 +1,135 @@
+package amaro_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestAppRoute(t *testing.T) {
+	users := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithErrorHandler(func(c *amaro.Context, err error, code int) {
+			c.String(http.StatusTeapot, "users: "+err.Error())
+		}),
+	)
+	users.Use(trace("users"))
+	users.Handle(http.MethodGet, "/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, c.PathParam("org")+"/"+c.PathParam("id"))
+	}, amaro.WithName("user.show"))
+	users.GET("/", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "list")
+	})
+	users.POST("/:id/fail", func(c *amaro.Context) error {
+		return errors.New("boom")
+	})
+
+	billing := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	billing.GET("/invoices", func(c *amaro.Context) error {
+		return errors.New("unavailable")
+	})
+
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithErrorHandler(func(c *amaro.Context, err error, code int) {
+			c.String(code, "app: "+err.Error())
+		}),
+	)
+	app.Use(trace("app"))
+	if err := app.Route("/orgs/:org/users", users); err != nil {
+		t.Fatal(err)
+	}
+	api := app.Group("/api")
+	api.Use(trace("api"))
+	if err := api.Route("/billing", billing); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+		trace  string
+	}{
+		{http.MethodGet, "/orgs/acme/users/42", http.StatusOK, "acme/42", "app,users"},
+		{http.MethodGet, "/orgs/acme/users", http.StatusOK, "list", "app,users"},
+		// Child error handler
+		{http.MethodPost, "/orgs/acme/users/42/fail", http.StatusTeapot, "users: boom", "app,users"},
+		// Child without error handler falls back to the parent's
+		{http.MethodGet, "/api/billing/invoices", http.StatusInternalServerError, "app: unavailable", "app,api"},
+	}
+	for _, tc := range cases {
+		w := app.Test(httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.code, w.Code)
+		}
+		if w.Body.String() != tc.body {
+			t.Errorf("%s %s: expected body %q, got %q", tc.method, tc.path, tc.body, w.Body.String())
+		}
+		if got := strings.Join(w.Header()["X-Trace"], ","); got != tc.trace {
+			t.Errorf("%s %s: expected trace %s, got %s", tc.method, tc.path, tc.trace, got)
+		}
+	}
+
+	// Mounted routes are part of the parent's routing table
+	url, err := app.URL("user.show", "acme", 7)
+	if err != nil || url != "/orgs/acme/users/7" {
+		t.Errorf("Expected /orgs/acme/users/7, got %q (%v)", url, err)
+	}
+	if got := len(app.Routes()); got != 4 {
+		t.Errorf("Expected 4 routes, got %d", got)
+	}
+}
+
+func TestMountStripPrefix(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Forwarded-Prefix", r.Header.Get("X-Forwarded-Prefix"))
+		w.Write([]byte(r.URL.Path + "|" + r.URL.EscapedPath() + "|" + amaro.OriginalPath(r)))
+	})
+
+	app.Mount("/debug", echo, amaro.WithStripPrefix())
+	tenants := app.Group("/tenants/:tenant")
+	tenants.Group("/v1").Mount("/gateway", echo, amaro.WithStripPrefix())
+	app.Mount("/raw", echo)
+	inner := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	inner.Mount("/echo", echo, amaro.WithStripPrefix())
+	app.Mount("/nested", inner, amaro.WithStripPrefix())
+
+	tests := []struct {
+		path   string
+		body   string
+		prefix string
+	}{
+		{"/debug", "/|/|/debug", "/debug"},
+		{"/debug/pprof/heap", "/pprof/heap|/pprof/heap|/debug/pprof/heap", "/debug"},
+		{"/tenants/acme/v1/gateway/users/7", "/users/7|/users/7|/tenants/acme/v1/gateway/users/7", "/tenants/acme/v1/gateway"},
+		{"/tenants/acme/v1/gateway/files/a%2Fb", "/files/a/b|/files/a%2Fb|/tenants/acme/v1/gateway/files/a/b", "/tenants/acme/v1/gateway"},
+		{"/nested/echo/x", "/x|/x|/nested/echo/x", "/nested/echo"},
+		{"/raw/x", "/raw/x|/raw/x|/raw/x", "/spoofed"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-Forwarded-Prefix", "/spoofed")
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: Expected status 200, got %d", tt.path, rec.Code)
+		}
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: Expected body %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+		if got := rec.Header().Get("X-Forwarded-Prefix"); got != tt.prefix {
+			t.Errorf("%s: Expected X-Forwarded-Prefix %q, got %q", tt.path, tt.prefix, got)
+		}
+	}
+}
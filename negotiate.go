@@ -0,0 +1,294 @@
+package amaro
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Encoder writes v to w in the format of its content type, see WithEncoder.
+type Encoder func(w io.Writer, v interface{}) error
+
+// Decoder reads a request body in the format of its content type into v, see WithDecoder.
+type Decoder func(r io.Reader, v interface{}) error
+
+// contentEncoder is an Encoder registered for a content type.
+type contentEncoder struct {
+	contentType string // sent as Content-Type, e.g. "text/plain; charset=utf-8"
+	mediaType   string // matched against Accept, e.g. "text/plain"
+	encode      Encoder
+}
+
+// EncodeJSON, EncodeXML and EncodeText are the Encoders Negotiate uses by default.
+func EncodeJSON(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func EncodeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func EncodeText(w io.Writer, v interface{}) error {
+	_, err := fmt.Fprint(w, v)
+	return err
+}
+
+// DecodeJSON and DecodeXML are the Decoders Bind uses by default.
+func DecodeJSON(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func DecodeXML(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// defaultEncoders are offered by Negotiate in this order of preference.
+var defaultEncoders = []contentEncoder{
+	{"application/json", "application/json", EncodeJSON},
+	{"application/xml; charset=utf-8", "application/xml", EncodeXML},
+	{"text/plain; charset=utf-8", "text/plain", EncodeText},
+}
+
+var defaultDecoders = map[string]Decoder{
+	"application/json": DecodeJSON,
+	"application/xml":  DecodeXML,
+	"text/xml":         DecodeXML,
+}
+
+// WithEncoder returns an AppOption that lets Negotiate respond with contentType, such as
+// "application/yaml" or "text/csv; charset=utf-8". An encoder registered for a media type that
+// is already offered replaces it; new ones are preferred least when the client accepts several.
+func WithEncoder(contentType string, encode Encoder) AppOption {
+	return func(app *App) {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			panic(fmt.Sprintf("WithEncoder: invalid content type %q: %v", contentType, err))
+		}
+		if app.encoders == nil {
+			app.encoders = slices.Clone(defaultEncoders)
+		}
+		for i, r := range app.encoders {
+			if r.mediaType == mediaType {
+				app.encoders[i] = contentEncoder{contentType, mediaType, encode}
+				return
+			}
+		}
+		app.encoders = append(app.encoders, contentEncoder{contentType, mediaType, encode})
+	}
+}
+
+// WithDecoder returns an AppOption that lets Bind read request bodies of mediaType,
+// such as "application/yaml", replacing the Decoder registered for it.
+func WithDecoder(mediaType string, decode Decoder) AppOption {
+	return func(app *App) {
+		if app.decoders == nil {
+			app.decoders = maps.Clone(defaultDecoders)
+		}
+		app.decoders[strings.ToLower(mediaType)] = decode
+	}
+}
+
+func (c *Context) encoders() []contentEncoder {
+	if c.app != nil && c.app.encoders != nil {
+		return c.app.encoders
+	}
+	return defaultEncoders
+}
+
+func (c *Context) decoder(mediaType string) Decoder {
+	if c.app != nil && c.app.decoders != nil {
+		return c.app.decoders[mediaType]
+	}
+	return defaultDecoders[mediaType]
+}
+
+// Negotiate writes v in the format the client prefers according to the Accept header, with
+// JSON, XML and plain text available by default, see WithEncoder. Without an Accept
+// header the first encoder, JSON, is used. If no format is acceptable Negotiate returns an
+// HTTPError with status 406 and writes nothing.
+func (c *Context) Negotiate(statusCode int, v interface{}) error {
+	var accept string
+	if c.Request != nil {
+		accept = strings.Join(c.Request.Header.Values("Accept"), ",")
+	}
+	encoder, ok := negotiate(accept, c.encoders())
+	c.Writer.Header().Add("Vary", "Accept")
+	if !ok {
+		return NewHTTPError(http.StatusNotAcceptable)
+	}
+	return c.Encode(statusCode, encoder.contentType, encoder.encode, v)
+}
+
+// Encode writes v with encode and sets the Content-Type header to contentType.
+func (c *Context) Encode(statusCode int, contentType string, encode Encoder, v interface{}) error {
+	c.Writer.Header().Set("Content-Type", contentType)
+	c.Writer.WriteHeader(statusCode)
+	return encode(c.Writer, v)
+}
+
+// XML writes v as XML, preceded by the standard XML header.
+func (c *Context) XML(statusCode int, v interface{}) error {
+	return c.Encode(statusCode, "application/xml; charset=utf-8", EncodeXML, v)
+}
+
+// Bind binds the request to v according to its Content-Type: JSON and XML bodies are
+// decoded, see WithDecoder, and form bodies are bound like BindForm. Requests without a body,
+// such as GETs, are bound from the query string like BindQuery. Other content types are
+// rejected with an HTTPError with status 415.
+func (c *Context) Bind(v interface{}) error {
+	contentType := c.Request.Header.Get("Content-Type")
+	if contentType == "" && (c.Request.Body == nil || c.Request.Body == http.NoBody || c.Request.ContentLength == 0) {
+		return c.BindQuery(v)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return NewHTTPError(http.StatusUnsupportedMediaType).SetInternal(err)
+	}
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return c.BindForm(v)
+	case "multipart/form-data":
+		if err := checkPtr(v); err != nil {
+			return err
+		}
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+		if err := bindData(v, c.Request.Form, "form"); err != nil {
+			return err
+		}
+		return ValidateStruct(v)
+	}
+
+	decode := c.decoder(mediaType)
+	if decode == nil {
+		return NewHTTPError(http.StatusUnsupportedMediaType)
+	}
+	return c.bindBody(decode, v)
+}
+
+// BindXML binds the XML request body to the provided struct.
+func (c *Context) BindXML(v interface{}) error {
+	return c.bindBody(DecodeXML, v)
+}
+
+func (c *Context) bindBody(decode Decoder, v interface{}) error {
+	if c.Request.Body == nil {
+		return errors.New("request body is empty")
+	}
+	if err := checkPtr(v); err != nil {
+		return err
+	}
+	if err := decode(c.Request.Body, v); err != nil {
+		return err
+	}
+	return ValidateStruct(v)
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+	index        int
+}
+
+// parseAccept returns the media ranges of an Accept header in the order they appear.
+// Malformed ranges are skipped.
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q, index: i})
+	}
+	return ranges
+}
+
+// specificity ranks how closely r matches mediaType: 3 for an exact match, 2 for type/*,
+// 1 for */* and 0 if it does not match.
+func (r acceptRange) specificity(mediaType string) int {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	switch {
+	case r.typ == "*" && r.subtype == "*":
+		return 1
+	case r.typ != typ:
+		return 0
+	case r.subtype == "*":
+		return 2
+	case r.subtype == subtype:
+		return 3
+	}
+	return 0
+}
+
+// negotiate picks the encoder the client prefers. Each encoder gets the quality of the most
+// specific range matching it (RFC 9110, Section 12.5.1); ties go to the more specific match,
+// then the range listed first, then the encoder registered first.
+func negotiate(accept string, encoders []contentEncoder) (contentEncoder, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if len(encoders) == 0 {
+			return contentEncoder{}, false
+		}
+		return encoders[0], true
+	}
+
+	type candidate struct {
+		encoder     contentEncoder
+		q           float64
+		specificity int
+		index       int
+	}
+	var candidates []candidate
+	ranges := parseAccept(accept)
+	for _, encoder := range encoders {
+		best := candidate{encoder: encoder}
+		for _, r := range ranges {
+			if s := r.specificity(encoder.mediaType); s > best.specificity {
+				best.q, best.specificity, best.index = r.q, s, r.index
+			}
+		}
+		if best.specificity > 0 && best.q > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+	if len(candidates) == 0 {
+		return contentEncoder{}, false
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.q != b.q {
+			return a.q > b.q
+		}
+		if a.specificity != b.specificity {
+			return a.specificity > b.specificity
+		}
+		return a.index < b.index
+	})
+	return candidates[0].encoder, true
+}
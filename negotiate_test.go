@@ -0,0 +1,137 @@
+package amaro_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+type negotiateItem struct {
+	XMLName xml.Name `json:"-" xml:"item"`
+	ID      int      `json:"id" xml:"id" query:"id" form:"id"`
+	Name    string   `json:"name" xml:"name" query:"name" form:"name" validate:"required"`
+}
+
+func (i negotiateItem) String() string {
+	return fmt.Sprintf("%d %s", i.ID, i.Name)
+}
+
+func TestNegotiate(t *testing.T) {
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithEncoder("text/csv; charset=utf-8", func(w io.Writer, v interface{}) error {
+			item := v.(negotiateItem)
+			_, err := fmt.Fprintf(w, "%d,%s\n", item.ID, item.Name)
+			return err
+		}),
+	)
+	app.GET("/item", func(c *amaro.Context) error {
+		return c.Negotiate(http.StatusOK, negotiateItem{ID: 1, Name: "widget"})
+	})
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"", http.StatusOK, "application/json", `{"id":1,"name":"widget"}` + "\n"},
+		{"*/*", http.StatusOK, "application/json", `{"id":1,"name":"widget"}` + "\n"},
+		{"application/xml", http.StatusOK, "application/xml; charset=utf-8", xml.Header + "<item><id>1</id><name>widget</name></item>"},
+		{"text/plain", http.StatusOK, "text/plain; charset=utf-8", "1 widget"},
+		{"text/csv", http.StatusOK, "text/csv; charset=utf-8", "1,widget\n"},
+		{"application/json;q=0.5, application/xml;q=0.9", http.StatusOK, "application/xml; charset=utf-8", ""},
+		{"text/*;q=0.8, text/csv", http.StatusOK, "text/csv; charset=utf-8", ""},
+		{"text/*", http.StatusOK, "text/plain; charset=utf-8", ""},
+		{"*/*;q=0.1, application/json;q=0", http.StatusOK, "application/xml; charset=utf-8", ""},
+		{"image/png", http.StatusNotAcceptable, "", ""},
+		{"application/json;q=0", http.StatusNotAcceptable, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/item", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := app.Test(req)
+		if w.Code != tt.code {
+			t.Errorf("Accept %q: Expected status %d, got %d", tt.accept, tt.code, w.Code)
+			continue
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("Accept %q: Expected Content-Type %q, got %q", tt.accept, tt.contentType, w.Header().Get("Content-Type"))
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("Accept %q: Expected body %q, got %q", tt.accept, tt.body, w.Body.String())
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: Expected Vary: Accept", tt.accept)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.Handle(http.MethodPost, "/items", func(c *amaro.Context) error {
+		var item negotiateItem
+		if err := c.Bind(&item); err != nil {
+			return err
+		}
+		return c.Negotiate(http.StatusCreated, item)
+	})
+	app.GET("/items", func(c *amaro.Context) error {
+		var item negotiateItem
+		if err := c.Bind(&item); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, item.String())
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		code        int
+	}{
+		{"JSON", "application/json; charset=utf-8", []byte(`{"id":3,"name":"gear"}`), http.StatusCreated},
+		{"XML", "application/xml", []byte(`<item><id>3</id><name>gear</name></item>`), http.StatusCreated},
+		{"Form", "application/x-www-form-urlencoded", []byte("id=3&name=gear"), http.StatusCreated},
+		{"Unsupported", "application/yaml", []byte("id: 3"), http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("Accept", "text/plain")
+			w := app.Test(req)
+			if w.Code != tt.code {
+				t.Fatalf("Expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code == http.StatusCreated && w.Body.String() != "3 gear" {
+				t.Errorf("Expected %q, got %q", "3 gear", w.Body.String())
+			}
+		})
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		w := app.Test(httptest.NewRequest(http.MethodGet, "/items?id=4&name=bolt", nil))
+		if w.Body.String() != "4 bolt" {
+			t.Errorf("Expected %q, got %q", "4 bolt", w.Body.String())
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`<item><id>5</id></item>`))
+		req.Header.Set("Content-Type", "text/xml")
+		if w := app.Test(req); w.Code == http.StatusCreated {
+			t.Error("Expected missing name to fail validation")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package amaro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+)
+
+// RequestIDKey is the Context key the request ID is stored under, e.g. by middlewares.RequestID.
+const RequestIDKey = "request_id"
+
+// ErrValidation is wrapped by the errors of Bind and its variants when the bound struct
+// fails its validate tags.
+var ErrValidation = errors.New("validation failed")
+
+// ProblemDetails is an RFC 9457 problem details object. Handlers can return it as an error,
+// and ProblemErrorHandler renders every error as one.
+type ProblemDetails struct {
+	// Type is a URI identifying the problem type. An empty Type means "about:blank",
+	// in which case Title is the status text.
+	Type string `json:"type,omitempty"`
+
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status is the HTTP status code.
+	Status int `json:"status,omitempty"`
+
+	// Detail explains this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI identifying this occurrence of the problem.
+	// ProblemErrorHandler sets it to the request path if empty.
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are additional members, such as "errors" for invalid fields. They are
+	// serialized next to the standard members, which they cannot override.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem returns a ProblemDetails with status, its status text as title and detail.
+func NewProblem(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// With sets the extension member key to value and returns p.
+func (p *ProblemDetails) With(key string, value interface{}) *ProblemDetails {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("%d %s", p.Status, p.Title)
+}
+
+// MarshalJSON serializes the standard members together with the extensions.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	type standard ProblemDetails
+	data, err := json.Marshal((*standard)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	var std map[string]interface{}
+	if err := json.Unmarshal(data, &std); err != nil {
+		return nil, err
+	}
+	for k, v := range std {
+		members[k] = v
+	}
+	return json.Marshal(members)
+}
+
+// Problem converts err into a ProblemDetails, using code as the status of errors that do not
+// carry one. A ProblemDetails is returned as a copy. HTTPErrors keep their status and message,
+// where a message that is not a string becomes the "message" member. Validation errors become
+// 422 Unprocessable Content, listing ValidationErrors in "errors" as objects with the members
+// field, rule, param and detail. 405 responses list the allowed methods in "allowed". The
+// messages of other errors are only shown for 4xx statuses, so internal errors do not leak.
+func Problem(err error, code int) *ProblemDetails {
+	var pd *ProblemDetails
+	if errors.As(err, &pd) {
+		problem := *pd
+		problem.Extensions = maps.Clone(pd.Extensions)
+		if problem.Status == 0 {
+			problem.Status = code
+		}
+		if problem.Type == "" && problem.Title == "" {
+			problem.Title = http.StatusText(problem.Status)
+		}
+		return &problem
+	}
+
+	var he *HTTPError
+	if errors.As(err, &he) {
+		problem := NewProblem(he.Code, "")
+		switch msg := he.Message.(type) {
+		case nil:
+		case string:
+			if msg != problem.Title {
+				problem.Detail = msg
+			}
+		case error:
+			problem.Detail = msg.Error()
+		case fmt.Stringer:
+			problem.Detail = msg.String()
+		default:
+			problem.With("message", msg)
+		}
+		return problem
+	}
+
+	var mna *MethodNotAllowedError
+	if errors.As(err, &mna) {
+		return NewProblem(http.StatusMethodNotAllowed, "").With("allowed", mna.Allowed)
+	}
+
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		fields := make([]map[string]string, len(verrs))
+		for i, fe := range verrs {
+			fields[i] = map[string]string{"field": fe.Field, "rule": fe.Rule, "param": fe.Param, "detail": fe.Error()}
+		}
+		return NewProblem(http.StatusUnprocessableEntity, ErrValidation.Error()).With("errors", fields)
+	}
+
+	if errors.Is(err, ErrValidation) {
+		return NewProblem(http.StatusUnprocessableEntity, err.Error())
+	}
+
+	if code >= 400 && code < 500 {
+		return NewProblem(code, err.Error())
+	}
+	return NewProblem(code, "")
+}
+
+// ProblemErrorHandler is an ErrorHandler that writes errors as RFC 9457 application/problem+json
+// responses, see Problem. The request ID stored under RequestIDKey is added as "request_id".
+//
+//	app := amaro.New(amaro.WithErrorHandler(amaro.ProblemErrorHandler))
+func ProblemErrorHandler(c *Context, err error, code int) {
+	problem := Problem(err, code)
+	if problem.Instance == "" && c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+	if id, ok := c.Get(RequestIDKey); ok {
+		problem.With("request_id", id)
+	}
+	writeProblem(c, problem)
+}
+
+// writeProblem writes problem unless a response has already been started.
+func writeProblem(c *Context, problem *ProblemDetails) {
+	if c.Response().Written() {
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "application/problem+json")
+	c.Writer.Header().Del("Content-Length")
+	c.Writer.WriteHeader(problem.Status)
+	json.NewEncoder(c.Writer).Encode(problem)
+}
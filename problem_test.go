@@ -0,0 +1,151 @@
+package amaro_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/middlewares"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestProblemErrorHandler(t *testing.T) {
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithErrorHandler(amaro.ProblemErrorHandler),
+	)
+	app.Use(middlewares.RequestID())
+
+	outOfCredit := &amaro.ProblemDetails{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: http.StatusForbidden,
+		Detail: "Your current balance is 30, but that costs 50.",
+	}
+	outOfCredit.With("balance", 30)
+
+	app.GET("/credit", func(c *amaro.Context) error {
+		return outOfCredit
+	})
+	app.GET("/teapot", func(c *amaro.Context) error {
+		return amaro.NewHTTPError(http.StatusTeapot, map[string]string{"brew": "coffee"})
+	})
+	app.GET("/conflict", func(c *amaro.Context) error {
+		return amaro.NewHTTPError(http.StatusConflict, "name already taken")
+	})
+	app.GET("/internal", func(c *amaro.Context) error {
+		return errors.New("connection refused: 10.0.0.1:5432")
+	})
+	app.POST("/users", func(c *amaro.Context) error {
+		var user struct {
+			Name string `json:"name" validate:"required"`
+		}
+		return c.Bind(&user)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		want   map[string]interface{}
+	}{
+		{"ProblemDetails", http.MethodGet, "/credit", http.StatusForbidden, map[string]interface{}{
+			"type":     "https://example.com/probs/out-of-credit",
+			"title":    "You do not have enough credit.",
+			"detail":   "Your current balance is 30, but that costs 50.",
+			"instance": "/credit",
+			"balance":  30.0,
+		}},
+		{"HTTPErrorMessage", http.MethodGet, "/teapot", http.StatusTeapot, map[string]interface{}{
+			"title":   "I'm a teapot",
+			"message": map[string]interface{}{"brew": "coffee"},
+		}},
+		{"HTTPErrorDetail", http.MethodGet, "/conflict", http.StatusConflict, map[string]interface{}{
+			"title":  "Conflict",
+			"detail": "name already taken",
+		}},
+		{"Internal", http.MethodGet, "/internal", http.StatusInternalServerError, map[string]interface{}{
+			"title":  "Internal Server Error",
+			"detail": nil,
+		}},
+		{"NotFound", http.MethodGet, "/missing", http.StatusNotFound, map[string]interface{}{
+			"title":    "Not Found",
+			"instance": "/missing",
+		}},
+		{"MethodNotAllowed", http.MethodDelete, "/credit", http.StatusMethodNotAllowed, map[string]interface{}{
+			"title":   "Method Not Allowed",
+			"allowed": []interface{}{"GET"},
+		}},
+		{"Validation", http.MethodPost, "/users", http.StatusUnprocessableEntity, map[string]interface{}{
+			"title":  "Unprocessable Entity",
+			"detail": "validation failed",
+			"errors": []interface{}{map[string]interface{}{
+				"field": "Name", "rule": "required", "param": "", "detail": "field 'Name' is required",
+			}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Request-ID", "req-42")
+			w := app.Test(req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Expected application/problem+json, got %q", ct)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Invalid JSON %q: %v", w.Body.String(), err)
+			}
+			if got["status"] != float64(tt.status) {
+				t.Errorf("Expected status member %d, got %v", tt.status, got["status"])
+			}
+			if got["request_id"] != "req-42" {
+				t.Errorf("Expected request_id req-42, got %v", got["request_id"])
+			}
+			for k, v := range tt.want {
+				if !reflect.DeepEqual(got[k], v) {
+					t.Errorf("Expected %s = %#v, got %#v", k, v, got[k])
+				}
+			}
+		})
+	}
+
+	if _, ok := outOfCredit.Extensions["request_id"]; ok {
+		t.Error("Expected the returned ProblemDetails not to be modified")
+	}
+}
+
+func TestProblemDetailsMarshalJSON(t *testing.T) {
+	problem := amaro.NewProblem(http.StatusBadRequest, "bad input").With("status", "ignored").With("field", "name")
+	data, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"detail":"bad input","field":"name","status":400,"title":"Bad Request"}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
+
+func TestDefaultErrorHandlerProblem(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.GET("/", func(c *amaro.Context) error {
+		return amaro.NewProblem(http.StatusPaymentRequired, "")
+	})
+
+	w := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusPaymentRequired || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected 402 problem+json, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
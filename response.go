@@ -0,0 +1,146 @@
+package amaro
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter is the http.ResponseWriter of a Context. It records the status code and the
+// number of body bytes written, and runs hooks just before the header is written.
+//
+// Middlewares that transform the body, such as compression, replace Writer with a wrapper
+// instead of replacing Context.Writer. As long as the wrapper implements Unwrap, Flush and
+// Hijack still reach the connection, so streaming and websockets keep working behind them.
+type ResponseWriter struct {
+	// Writer receives the header and body. It is the server's response writer unless
+	// a middleware has wrapped it.
+	Writer http.ResponseWriter
+
+	status  int
+	size    int64
+	written bool
+	before  []func()
+}
+
+// NewResponseWriter returns a ResponseWriter writing to w.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{Writer: w}
+}
+
+// reset prepares the ResponseWriter for a new request, keeping the hook slice's capacity.
+func (w *ResponseWriter) reset(rw http.ResponseWriter) {
+	w.Writer = rw
+	w.status = 0
+	w.size = 0
+	w.written = false
+	clear(w.before)
+	w.before = w.before[:0]
+}
+
+// Before registers a hook that runs just before the header is written, e.g. to set headers
+// that depend on the response. Hooks run in the order they were added. If the handler writes
+// nothing, the App sends the implicit 200 header once it returns, so hooks still run.
+func (w *ResponseWriter) Before(hook func()) {
+	w.before = append(w.before, hook)
+}
+
+// Status returns the status code written, or http.StatusOK if the header has not been
+// written yet, which is what the server sends by default.
+func (w *ResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Size returns the number of body bytes written so far.
+func (w *ResponseWriter) Size() int64 {
+	return w.size
+}
+
+// Written reports whether the header has been written.
+func (w *ResponseWriter) Written() bool {
+	return w.written
+}
+
+// Header returns the header map that will be sent by WriteHeader.
+func (w *ResponseWriter) Header() http.Header {
+	return w.Writer.Header()
+}
+
+// WriteHeader runs the Before hooks and sends the header with statusCode.
+// Later calls are ignored, except for informational 1xx responses, which may precede the final one.
+func (w *ResponseWriter) WriteHeader(statusCode int) {
+	if w.written {
+		return
+	}
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		w.Writer.WriteHeader(statusCode)
+		return
+	}
+
+	w.written = true
+	w.status = statusCode
+	for _, hook := range w.before {
+		hook()
+	}
+	w.Writer.WriteHeader(statusCode)
+}
+
+// Write writes the body, sending the header with http.StatusOK first if needed.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.Writer.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// ReadFrom copies r to the body, letting the server use sendfile where it can.
+func (w *ResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	var n int64
+	var err error
+	if rf, ok := w.Writer.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		// Hide ReadFrom so io.Copy does not call back into it
+		n, err = io.Copy(struct{ io.Writer }{w.Writer}, r)
+	}
+	w.size += n
+	return n, err
+}
+
+// Flush sends any buffered data to the client, see http.ResponseController.Flush.
+func (w *ResponseWriter) Flush() {
+	w.FlushError()
+}
+
+// FlushError is like Flush but returns http.ErrNotSupported if Writer cannot flush.
+func (w *ResponseWriter) FlushError() error {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return http.NewResponseController(w.Writer).Flush()
+}
+
+// Hijack lets the handler take over the connection, see http.ResponseController.Hijack.
+// The response counts as written afterwards.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.Writer).Hijack()
+	if err == nil && !w.written {
+		w.written = true
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns Writer, so http.ResponseController can reach its optional methods.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.Writer
+}
@@ -0,0 +1,125 @@
+package amaro_test
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/addons/cache"
+	"github.com/buildwithgo/amaro/middlewares"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestResponseWriter(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	var status int
+	var size int64
+	app.Use(func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			c.Response().Before(func() {
+				c.SetHeader("X-Status-Seen", "yes")
+			})
+			err := next(c)
+			status, size = c.Response().Status(), c.Response().Size()
+			return err
+		}
+	})
+	app.GET("/created", func(c *amaro.Context) error {
+		if c.Response().Written() {
+			t.Error("Expected response not to be written yet")
+		}
+		c.Status(http.StatusCreated)
+		c.Status(http.StatusTeapot) // superfluous, ignored
+		_, err := io.Copy(c.Writer, strings.NewReader("hello"))
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/created", nil))
+	if w.Code != http.StatusCreated || status != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d (recorded %d)", w.Code, status)
+	}
+	if size != 5 || w.Body.String() != "hello" {
+		t.Errorf("Expected 5 bytes, got %d %q", size, w.Body.String())
+	}
+	if w.Header().Get("X-Status-Seen") != "yes" {
+		t.Error("Expected Before hook to run before the header was written")
+	}
+
+	// Handlers writing nothing get the implicit 200 with the hooks applied
+	app.GET("/empty", func(c *amaro.Context) error { return nil })
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	if w.Code != http.StatusOK || status != http.StatusOK || size != 0 || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 200, got %d %q (recorded %d)", w.Code, w.Body.String(), status)
+	}
+	if w.Header().Get("X-Status-Seen") != "yes" {
+		t.Error("Expected Before hook to run for an empty response")
+	}
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.Use(middlewares.Logger(middlewares.WithLogger(log.New(io.Discard, "", 0))))
+	app.Use(middlewares.Compress())
+	app.GET("/events", func(c *amaro.Context) error {
+		io.WriteString(c.Writer, "data: 1\n\n")
+		return http.NewResponseController(c.Writer).Flush()
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	app.ServeHTTP(w, req)
+	if !w.Flushed {
+		t.Error("Expected the response to be flushed through Logger and Compress")
+	}
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	app.Use(middlewares.Logger(middlewares.WithLogger(log.New(io.Discard, "", 0))))
+	app.GET("/raw", func(c *amaro.Context) error {
+		conn, rw, err := http.NewResponseController(c.Writer).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString(line)
+		return rw.Flush()
+	}, cache.CachePage(cache.NewMemoryCache(), time.Minute))
+
+	ts := httptest.NewServer(app)
+	defer ts.Close()
+
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET /raw HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	io.WriteString(conn, "ping\n")
+	if line, _ := br.ReadString('\n'); line != "ping\n" {
+		t.Errorf("Expected echo, got %q", line)
+	}
+}
@@ -0,0 +1,98 @@
+package amaro
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BuildPath fills the parameters and wildcard of pattern with params, in the order
+// they appear, using the parsers of the configuration.
+// Parameter values are path-escaped; wildcard values keep their slashes.
+// Optional trailing parameters without a value are left out.
+func (config RouterConfig) BuildPath(pattern string, params ...interface{}) (string, error) {
+	next := 0
+	value := func(name string) (string, error) {
+		if next >= len(params) {
+			return "", fmt.Errorf("missing value for parameter %q in %s", name, pattern)
+		}
+		v := fmt.Sprint(params[next])
+		next++
+		return v, nil
+	}
+
+	segments := strings.Split(pattern, "/")
+	out := segments[:0]
+	for _, segment := range segments {
+		if segment == "" {
+			out = append(out, segment)
+			continue
+		}
+
+		if config.OptionalParser != nil {
+			if stripped, ok := config.OptionalParser(segment); ok && config.IsParam(stripped) {
+				if next >= len(params) {
+					// No value left for an optional trailing param
+					continue
+				}
+				segment = stripped
+			}
+		}
+
+		if config.SegmentParser != nil {
+			if parts := config.SegmentParser(segment); parts != nil {
+				var b strings.Builder
+				for _, part := range parts {
+					if !part.Param {
+						b.WriteString(part.Value)
+						continue
+					}
+					v, err := value(part.Value)
+					if err != nil {
+						return "", err
+					}
+					b.WriteString(url.PathEscape(v))
+				}
+				out = append(out, b.String())
+				continue
+			}
+		}
+
+		if config.ParamParser != nil {
+			if isParam, name := config.ParamParser(segment); isParam {
+				v, err := value(name)
+				if err != nil {
+					return "", err
+				}
+				out = append(out, url.PathEscape(v))
+				continue
+			}
+		}
+
+		if config.WildcardParser != nil {
+			if isWildcard, name := config.WildcardParser(segment); isWildcard {
+				v, err := value(name)
+				if err != nil {
+					return "", err
+				}
+				parts := strings.Split(strings.TrimPrefix(v, "/"), "/")
+				for j, part := range parts {
+					parts[j] = url.PathEscape(part)
+				}
+				out = append(out, strings.Join(parts, "/"))
+				continue
+			}
+		}
+
+		out = append(out, segment)
+	}
+
+	if next < len(params) {
+		return "", fmt.Errorf("too many values for %s: got %d, used %d", pattern, len(params), next)
+	}
+	path := strings.Join(out, "/")
+	if path == "" {
+		path = "/"
+	}
+	return path, nil
+}
@@ -0,0 +1,90 @@
+package amaro_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestRouteMeta(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	var seen amaro.RouteMeta
+	app.Handle(http.MethodGet, "/reports/:id", func(c *amaro.Context) error {
+		seen = c.RouteMeta()
+		return c.String(http.StatusOK, "report")
+	},
+		amaro.WithTags("reports"),
+		amaro.WithDescription("Fetch a report"),
+		amaro.WithScopes("reports:read"),
+		amaro.WithDeprecated(),
+		amaro.WithMeta("owner", "billing"),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/reports/1", nil)
+	if w := app.Test(req); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	if len(seen.Tags) != 1 || seen.Tags[0] != "reports" {
+		t.Errorf("Expected tags [reports], got %v", seen.Tags)
+	}
+	if seen.Description != "Fetch a report" {
+		t.Errorf("Unexpected description %q", seen.Description)
+	}
+	if len(seen.Scopes) != 1 || seen.Scopes[0] != "reports:read" {
+		t.Errorf("Expected scopes [reports:read], got %v", seen.Scopes)
+	}
+	if !seen.Deprecated {
+		t.Error("Expected route to be deprecated")
+	}
+	if v, ok := seen.Get("owner"); !ok || v != "billing" {
+		t.Errorf("Expected owner=billing, got %v", v)
+	}
+
+	routes := app.Routes()
+	if len(routes) != 1 || routes[0].Meta.Description != "Fetch a report" {
+		t.Errorf("Expected metadata in Routes(), got %+v", routes)
+	}
+}
+
+func TestContextRoute(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	var before, after string
+	app.Use(func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			before = c.RoutePath()
+			err := next(c)
+			after = c.RoutePath()
+			return err
+		}
+	})
+
+	var inHandler amaro.RouteInfo
+	var matched bool
+	app.Handle(http.MethodGet, "/users/:id/posts/:post", func(c *amaro.Context) error {
+		inHandler, matched = c.Route()
+		return nil
+	}, amaro.WithName("user.post"), amaro.WithTags("posts"))
+
+	app.Test(httptest.NewRequest(http.MethodGet, "/users/1/posts/2", nil))
+
+	if before != "" {
+		t.Errorf("Expected empty route path before routing, got %q", before)
+	}
+	if after != "/users/:id/posts/:post" {
+		t.Errorf("Expected route pattern after routing, got %q", after)
+	}
+	if !matched || inHandler.Name != "user.post" || inHandler.Method != http.MethodGet || inHandler.Meta.Tags[0] != "posts" {
+		t.Errorf("Expected matched route in handler, got %+v", inHandler)
+	}
+
+	app.Test(httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if after != "" {
+		t.Errorf("Expected empty route path for unmatched request, got %q", after)
+	}
+}
@@ -1,19 +1,156 @@
 package amaro
 
-import "io/fs"
+import (
+	"io/fs"
+	"strings"
+)
 
 // Route represents a registered route.
 type Route struct {
 	Method      string
 	Path        string
+	Host        string   // host pattern the route is scoped to, empty for any host
+	Listeners   []string // names of the listeners serving the route, empty for all
+	Name        string
+	Meta        RouteMeta
 	Handler     Handler
 	Middlewares []Middleware
 }
 
+// RouteInfo describes a registered route without giving access to its handler,
+// as returned by Context.Route. Meta shares its slices and map with the route,
+// so it must not be modified.
+type RouteInfo struct {
+	Method string
+	Path   string
+	Host   string
+	Name   string
+	Meta   RouteMeta
+}
+
+// RouteMeta holds descriptive metadata attached to a route.
+// It is available at request time through Context.RouteMeta and from Router.Routes,
+// so documentation generators and policy middlewares share one source of truth.
+type RouteMeta struct {
+	Tags        []string
+	Description string
+	Scopes      []string
+	Deprecated  bool
+	Extra       map[string]interface{}
+}
+
+// empty reports whether no metadata is set.
+func (m RouteMeta) empty() bool {
+	return len(m.Tags) == 0 && m.Description == "" && len(m.Scopes) == 0 && !m.Deprecated && len(m.Extra) == 0
+}
+
+// Get returns the custom value stored under key.
+func (m RouteMeta) Get(key string) (interface{}, bool) {
+	v, ok := m.Extra[key]
+	return v, ok
+}
+
+// RouteOption configures a Route before it is registered.
+// A Middleware is a RouteOption appending itself to the route middlewares, so both can
+// be passed when registering a route:
+//
+//	app.GET("/users/:id", showUser, auth, amaro.WithName("user.show"))
+type RouteOption interface {
+	applyRoute(route *Route)
+}
+
+// RouteOptionFunc adapts a function to a RouteOption.
+type RouteOptionFunc func(*Route)
+
+func (f RouteOptionFunc) applyRoute(route *Route) {
+	f(route)
+}
+
+func (m Middleware) applyRoute(route *Route) {
+	route.Middlewares = append(route.Middlewares, m)
+}
+
+// WithName sets the name of the route, used for reverse URL generation.
+func WithName(name string) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Name = name
+	})
+}
+
+// WithTags appends tags to the route metadata.
+func WithTags(tags ...string) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Meta.Tags = append(r.Meta.Tags, tags...)
+	})
+}
+
+// WithDescription sets the route description.
+func WithDescription(description string) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Meta.Description = description
+	})
+}
+
+// WithScopes appends scopes required to access the route.
+func WithScopes(scopes ...string) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Meta.Scopes = append(r.Meta.Scopes, scopes...)
+	})
+}
+
+// WithDeprecated marks the route as deprecated.
+func WithDeprecated() RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Meta.Deprecated = true
+	})
+}
+
+// WithMeta stores a custom key/value pair in the route metadata.
+func WithMeta(key string, value interface{}) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		if r.Meta.Extra == nil {
+			r.Meta.Extra = make(map[string]interface{})
+		}
+		r.Meta.Extra[key] = value
+	})
+}
+
+// WithListeners restricts the route to the named listeners started by Serve.
+// Other listeners answer 404 for it.
+func WithListeners(names ...string) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Listeners = names
+	})
+}
+
+// WithMiddlewares appends route-specific middlewares to the route.
+func WithMiddlewares(middlewares ...Middleware) RouteOption {
+	return RouteOptionFunc(func(r *Route) {
+		r.Middlewares = append(r.Middlewares, middlewares...)
+	})
+}
+
 // ParamParser defines a function that checks if a path segment is a parameter.
 // It returns true and the parameter name if it is, false otherwise.
 type ParamParser func(segment string) (bool, string)
 
+// OptionalParser defines a function that checks if a path segment is marked optional.
+// It returns the segment without the marker and true if it is.
+// Only trailing parameter segments may be optional.
+type OptionalParser func(segment string) (string, bool)
+
+// SegmentPart is a piece of a path segment: either literal text or a parameter.
+type SegmentPart struct {
+	Param bool
+	// Value is the literal text, or the parameter name including any constraint.
+	Value string
+}
+
+// SegmentParser defines a function that splits a path segment holding several parameters
+// separated by literals (e.g. ":name.:ext") into its parts.
+// It returns nil for static segments and segments that are a single parameter.
+type SegmentParser func(segment string) []SegmentPart
+
 // WildcardParser defines a function that checks if a path segment is a wildcard.
 // It returns true and the wildcard name if it is, false otherwise.
 type WildcardParser func(segment string) (bool, string)
@@ -22,8 +159,45 @@ type WildcardParser func(segment string) (bool, string)
 type RouterConfig struct {
 	ParamParser    ParamParser
 	WildcardParser WildcardParser
+
+	// OptionalParser detects optional trailing parameters. Optional parameters are disabled when nil.
+	OptionalParser OptionalParser
+	// SegmentParser detects segments with several parameters. They are disabled when nil.
+	SegmentParser SegmentParser
+
+	// ConstraintParser splits a parameter into its name and constraint.
+	// Parameters are unconstrained when nil.
+	ConstraintParser ConstraintParser
+	// Constraints maps constraint names (e.g. "int") to their implementation.
+	// Unknown constraint expressions are compiled as regular expressions.
+	Constraints map[string]Constraint
+
+	// TrailingSlash handles request paths whose trailing slash differs from the registered route.
+	// Routes ending with a wildcard accept both forms.
+	TrailingSlash PathPolicy
+	// CleanPath handles request paths with empty, "." or ".." segments.
+	// With PathLenient the route of the cleaned path is served, and the App rewrites the
+	// request path before its middlewares run so they see the path that is routed.
+	CleanPath PathPolicy
+	// Case handles request paths whose static segments only differ in letter case from
+	// the registered route. With PathStrict lookups are case-sensitive.
+	Case PathPolicy
 }
 
+// PathPolicy defines how a router treats a request path that only differs from the
+// canonical path of a route, e.g. by a trailing slash.
+type PathPolicy int
+
+const (
+	// PathStrict does not match the route.
+	PathStrict PathPolicy = iota
+	// PathLenient serves the route at the request path.
+	PathLenient
+	// PathRedirect redirects to the canonical path, with 301 for GET and HEAD requests
+	// and 308 otherwise so the method and body are kept.
+	PathRedirect
+)
+
 // DefaultParamParser implements the standard :param and {param} syntax.
 func DefaultParamParser(segment string) (bool, string) {
 	if len(segment) > 0 && segment[0] == ':' {
@@ -35,6 +209,99 @@ func DefaultParamParser(segment string) (bool, string) {
 	return false, ""
 }
 
+// DefaultOptionalParser implements the :param? syntax. It also accepts {param}?.
+func DefaultOptionalParser(segment string) (string, bool) {
+	if len(segment) > 1 && segment[len(segment)-1] == '?' {
+		return segment[:len(segment)-1], true
+	}
+	return segment, false
+}
+
+// DefaultSegmentParser implements multi-parameter segments such as ":name.:ext",
+// "{lat},{lng}" or "v{version}". A :param starts the segment or follows a separator
+// such as "." or "-", so static segments like "items:search" stay static. Its name is made
+// of letters, digits and underscores and may be followed by a <constraint>; a {param}
+// spans to its closing brace.
+func DefaultSegmentParser(segment string) []SegmentPart {
+	var parts []SegmentPart
+	params := 0
+	literalStart := 0
+
+	flush := func(end int) {
+		if end > literalStart {
+			parts = append(parts, SegmentPart{Value: segment[literalStart:end]})
+		}
+	}
+
+	for i := 0; i < len(segment); {
+		switch {
+		case segment[i] == '{':
+			end := closingBrace(segment, i)
+			if end < 0 {
+				i = len(segment)
+				continue
+			}
+			flush(i)
+			parts = append(parts, SegmentPart{Param: true, Value: segment[i+1 : end]})
+			params++
+			i = end + 1
+			literalStart = i
+
+		case segment[i] == ':' && (i == 0 || !isNameChar(segment[i-1])) && i+1 < len(segment) && isNameStart(segment[i+1]):
+			end := i + 1
+			for end < len(segment) && isNameChar(segment[end]) {
+				end++
+			}
+			if end < len(segment) && segment[end] == ':' {
+				// :name:constraint spans the rest of the segment
+				end = len(segment)
+			} else if end < len(segment) && segment[end] == '<' {
+				if j := strings.IndexByte(segment[end:], '>'); j >= 0 {
+					end += j + 1
+				}
+			}
+			flush(i)
+			parts = append(parts, SegmentPart{Param: true, Value: segment[i+1 : end]})
+			params++
+			i = end
+			literalStart = i
+
+		default:
+			i++
+		}
+	}
+	flush(len(segment))
+
+	if params == 0 || len(parts) == 1 {
+		return nil
+	}
+	return parts
+}
+
+func closingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c|0x20) >= 'a' && (c|0x20) <= 'z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
+
 // DefaultWildcardParser implements the standard *wildcard syntax.
 func DefaultWildcardParser(segment string) (bool, string) {
 	if len(segment) > 0 && segment[0] == '*' {
@@ -43,11 +310,30 @@ func DefaultWildcardParser(segment string) (bool, string) {
 	return false, ""
 }
 
+// IsParam reports whether segment is a param or a multi-param segment.
+func (config RouterConfig) IsParam(segment string) bool {
+	if config.SegmentParser != nil && config.SegmentParser(segment) != nil {
+		return true
+	}
+	if config.ParamParser != nil {
+		isParam, _ := config.ParamParser(segment)
+		return isParam
+	}
+	return false
+}
+
 // DefaultRouterConfig returns the default configuration.
 func DefaultRouterConfig() RouterConfig {
 	return RouterConfig{
-		ParamParser:    DefaultParamParser,
-		WildcardParser: DefaultWildcardParser,
+		ParamParser:      DefaultParamParser,
+		WildcardParser:   DefaultWildcardParser,
+		OptionalParser:   DefaultOptionalParser,
+		SegmentParser:    DefaultSegmentParser,
+		ConstraintParser: DefaultConstraintParser,
+		Constraints:      DefaultConstraints(),
+		TrailingSlash:    PathLenient,
+		CleanPath:        PathLenient,
+		Case:             PathStrict,
 	}
 }
 
@@ -69,6 +355,75 @@ type Router interface {
 	Routes() []Route
 }
 
+// RouteAdder is implemented by routers that register a fully described Route, keeping its
+// name, metadata and other options. Other routers get routes through Add.
+type RouteAdder interface {
+	AddRoute(route Route) error
+}
+
+// RouteReplacer is implemented by routers that can register a route again, replacing the
+// route registered for the same method and path. Groups use it to apply middlewares added
+// with Use to the routes registered before; with other routers those routes keep the
+// middlewares they were registered with.
+type RouteReplacer interface {
+	ReplaceRoute(route Route) error
+}
+
+// VersionedRouter is implemented by routers that count their registrations, including
+// those made on the router directly, so the App knows when its route name index is stale.
+// Without it the index is rebuilt when a name is not found.
+type VersionedRouter interface {
+	// Version returns a number that changes whenever a route is registered.
+	Version() uint64
+}
+
+// registerRoute adds route to router, with AddRoute if the router is a RouteAdder.
+// Other routers only get the method, path, handler and middlewares through Add,
+// so routes with a name, metadata or listeners are rejected.
+func registerRoute(router Router, route Route) error {
+	if ra, ok := router.(RouteAdder); ok {
+		return ra.AddRoute(route)
+	}
+	if route.Name != "" || len(route.Listeners) > 0 || !route.Meta.empty() {
+		return &RouteError{Method: route.Method, Path: route.Path, Err: ErrRouteOptionsUnsupported}
+	}
+	return router.Add(route.Method, route.Path, route.Handler, route.Middlewares...)
+}
+
+// ConfigurableRouter is implemented by routers that expose their RouterConfig.
+// The App uses it to build URLs with the same syntax the router parses.
+type ConfigurableRouter interface {
+	Config() RouterConfig
+}
+
+// HostRouter is implemented by routers that dispatch by host before path lookup.
+type HostRouter interface {
+	// Host returns a group whose routes only match requests for hosts matching pattern.
+	Host(pattern string) *Group
+}
+
+// failingRouter is the router of groups that cannot register routes, such as those
+// returned by App.Host for routers that are not HostRouters. Registrations fail with err.
+type failingRouter struct {
+	Router
+	err error
+}
+
+func (r failingRouter) Add(method, path string, handler Handler, middlewares ...Middleware) error {
+	return &RouteError{Method: method, Path: path, Err: r.err}
+}
+
+func (r failingRouter) AddRoute(route Route) error {
+	return &RouteError{Method: route.Method, Path: route.Path, Err: r.err}
+}
+
+// ValidatingRouter is implemented by routers that record failed registrations,
+// including those whose error was ignored, e.g. by StaticFS.
+type ValidatingRouter interface {
+	// Validate returns the errors of all failed registrations joined, or nil.
+	Validate() error
+}
+
 // WithRouter returns an AppOption that configures the App to use the specified router.
 func WithRouter(router Router) AppOption {
 	return func(app *App) {
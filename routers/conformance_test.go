@@ -0,0 +1,194 @@
+package routers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// routerCaps lists the features a router implementation may lack.
+type routerCaps struct {
+	multiParam  bool // multi-param segments such as /docs/:name.:ext
+	zeroAlloc   bool // Find does not allocate
+	overlapping bool // accepts overlapping patterns such as /a/comments/:id and /a/:id/lock
+}
+
+// allCaps are the features of the tree routers.
+var allCaps = routerCaps{multiParam: true, zeroAlloc: true, overlapping: true}
+
+// serveMuxCaps are the features of ServeMuxRouter, limited by http.ServeMux patterns.
+var serveMuxCaps = routerCaps{
+	// A ServeMux wildcard must be a whole segment
+	multiParam: false,
+	// The ServeMux allocates the path values of every match
+	zeroAlloc: false,
+	// The ServeMux rejects patterns where neither is more specific than the other
+	overlapping: false,
+}
+
+// testRouterConformance runs the matching rules every router implementation must share,
+// and those of the optional features in caps.
+func testRouterConformance(t *testing.T, newRouter func() amaro.Router, caps routerCaps) {
+	r := newRouter()
+	named := func(name string) amaro.Handler {
+		return func(c *amaro.Context) error { return fmt.Errorf("%s", name) }
+	}
+
+	routes := []string{
+		"/",
+		"/hello",
+		"/users",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/edit",
+		"/users/:id/posts/:post_id",
+		"/files/:id/meta",
+		"/files/*path",
+		"/static/*filepath",
+		"/a/b/c",
+		"/a/:x/:y/d",
+		"/items/{id:int}",
+		"/items/{slug}",
+		"/posts/:page?",
+		"/contact",
+		"/con",
+	}
+	if caps.multiParam {
+		routes = append(routes, "/docs/:name.:ext")
+	}
+	for _, path := range routes {
+		if err := r.GET(path, named(path)); err != nil {
+			t.Fatalf("failed to register %s: %v", path, err)
+		}
+	}
+	r.POST("/users", named("POST /users"))
+
+	cases := []struct {
+		path   string
+		want   string
+		params map[string]string
+	}{
+		{"/", "/", nil},
+		{"/hello", "/hello", nil},
+		{"/hello/", "/hello", nil},
+		{"/users", "/users", nil},
+		{"/users/new", "/users/new", nil},
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		{"/users/new/edit", "/users/:id/edit", map[string]string{"id": "new"}},
+		{"/users/1/posts/2", "/users/:id/posts/:post_id", map[string]string{"id": "1", "post_id": "2"}},
+		{"/files/1/meta", "/files/:id/meta", map[string]string{"id": "1"}},
+		{"/files/1/other", "/files/*path", map[string]string{"path": "1/other"}},
+		{"/static", "/static/*filepath", map[string]string{"filepath": ""}},
+		{"/static/css/main.css", "/static/*filepath", map[string]string{"filepath": "css/main.css"}},
+		{"/a/b/c", "/a/b/c", nil},
+		{"/a/b/c/d", "/a/:x/:y/d", map[string]string{"x": "b", "y": "c"}},
+		{"/items/7", "/items/{id:int}", map[string]string{"id": "7"}},
+		{"/items/seven", "/items/{slug}", map[string]string{"slug": "seven"}},
+		{"/posts", "/posts/:page?", nil},
+		{"/posts/3", "/posts/:page?", map[string]string{"page": "3"}},
+		{"/contact", "/contact", nil},
+		{"/con", "/con", nil},
+	}
+	if caps.multiParam {
+		cases = append(cases, struct {
+			path   string
+			want   string
+			params map[string]string
+		}{"/docs/guide.md", "/docs/:name.:ext", map[string]string{"name": "guide", "ext": "md"}})
+	}
+
+	ctx := amaro.NewContext(nil, nil)
+	for _, tc := range cases {
+		ctx.Reset(nil, nil)
+		route, err := r.Find(http.MethodGet, tc.path, ctx)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+			continue
+		}
+		if err := route.Handler(ctx); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %s handler, got %v", tc.path, tc.want, err)
+		}
+		if len(ctx.Params) != len(tc.params) {
+			t.Errorf("%s: unexpected params %v", tc.path, ctx.Params)
+		}
+		for k, v := range tc.params {
+			if got := ctx.PathParam(k); got != v {
+				t.Errorf("%s: expected %s=%q, got %q", tc.path, k, v, got)
+			}
+		}
+	}
+
+	for _, path := range []string{"/nope", "/co", "/contacts", "/users/1/posts", "/a/b"} {
+		ctx.Reset(nil, nil)
+		if _, err := r.Find(http.MethodGet, path, ctx); err == nil {
+			t.Errorf("%s: expected no match", path)
+		}
+	}
+
+	// Registered under another method
+	_, err := r.Find(http.MethodDelete, "/users", nil)
+	var mna *amaro.MethodNotAllowedError
+	if !errors.As(err, &mna) {
+		t.Errorf("Expected MethodNotAllowedError, got %v", err)
+	} else if mna.AllowHeader() != "GET, POST, OPTIONS" {
+		t.Errorf("Expected Allow 'GET, POST, OPTIONS', got %q", mna.AllowHeader())
+	}
+
+	// GET routes do not answer HEAD
+	_, err = r.Find(http.MethodHead, "/hello", nil)
+	if !errors.As(err, &mna) || mna.AllowHeader() != "GET, OPTIONS" {
+		t.Errorf("Expected HEAD /hello to be answered with Allow 'GET, OPTIONS', got %v", err)
+	}
+
+	if got := len(r.Routes()); got != len(routes)+1 {
+		t.Errorf("Expected %d routes, got %d", len(routes)+1, got)
+	}
+
+	// Same shape, different names
+	if err := r.GET("/users/:user_id/likes", named("conflict")); err == nil {
+		t.Error("Expected error for conflicting param name")
+	}
+	if err := r.GET("/files/*other", named("conflict")); err == nil {
+		t.Error("Expected error for conflicting wildcard name")
+	}
+	if err := r.GET("/items/{num:int}", named("conflict")); err == nil {
+		t.Error("Expected error for conflicting param name")
+	}
+
+	// Duplicates and routes shadowed by a wildcard
+	for _, path := range []string{"/users/:id", "/hello/", "/files/*path/edit"} {
+		err := r.GET(path, named("conflict"))
+		if !errors.Is(err, amaro.ErrRouteConflict) {
+			t.Errorf("%s: expected route conflict, got %v", path, err)
+		}
+	}
+	if err := r.(amaro.ValidatingRouter).Validate(); err == nil {
+		t.Error("Expected Validate to report the failed registrations")
+	}
+
+	// Matching stays allocation-free
+	if caps.zeroAlloc {
+		allocs := testing.AllocsPerRun(100, func() {
+			ctx.Reset(nil, nil)
+			_, _ = r.Find(http.MethodGet, "/a/b/c/d", ctx)
+		})
+		if allocs != 0 {
+			t.Errorf("Expected zero allocations, got %v", allocs)
+		}
+	}
+}
+
+func TestTrieRouter_Conformance(t *testing.T) {
+	testRouterConformance(t, func() amaro.Router { return NewTrieRouter() }, allCaps)
+}
+
+func TestRadixRouter_Conformance(t *testing.T) {
+	testRouterConformance(t, func() amaro.Router { return NewRadixRouter() }, allCaps)
+}
+
+func TestServeMuxRouter_Conformance(t *testing.T) {
+	testRouterConformance(t, func() amaro.Router { return NewServeMuxRouter() }, serveMuxCaps)
+}
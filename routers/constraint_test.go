@@ -0,0 +1,81 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+)
+
+func TestTrieRouter_Constraints(t *testing.T) {
+	r := NewTrieRouter()
+
+	r.GET("/files/{id:int}", func(c *amaro.Context) error { return fmt.Errorf("int") })
+	r.GET("/files/{uid:uuid}", func(c *amaro.Context) error { return fmt.Errorf("uuid") })
+	r.GET("/files/{name}", func(c *amaro.Context) error { return fmt.Errorf("name") })
+	r.GET("/orders/:code<[A-Z]{3}-[0-9]+>", func(c *amaro.Context) error { return fmt.Errorf("regex") })
+	r.GET("/users/{id:[0-9]+}/posts", func(c *amaro.Context) error { return fmt.Errorf("posts") })
+
+	cases := []struct {
+		path  string
+		want  string
+		param string
+		value string
+	}{
+		{"/files/42", "int", "id", "42"},
+		{"/files/-7", "int", "id", "-7"},
+		{"/files/123e4567-e89b-12d3-a456-426614174000", "uuid", "uid", "123e4567-e89b-12d3-a456-426614174000"},
+		{"/files/report.pdf", "name", "name", "report.pdf"},
+		{"/orders/ABC-12", "regex", "code", "ABC-12"},
+		{"/users/7/posts", "posts", "id", "7"},
+	}
+
+	ctx := amaro.NewContext(nil, nil)
+	for _, tc := range cases {
+		ctx.Reset(nil, nil)
+		route, err := r.Find(http.MethodGet, tc.path, ctx)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+			continue
+		}
+		if err := route.Handler(ctx); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %s handler, got %v", tc.path, tc.want, err)
+		}
+		if got := ctx.PathParam(tc.param); got != tc.value {
+			t.Errorf("%s: expected %s=%q, got %q", tc.path, tc.param, tc.value, got)
+		}
+	}
+
+	for _, path := range []string{"/orders/abc-12", "/orders/ABC-", "/users/abc/posts"} {
+		ctx.Reset(nil, nil)
+		if _, err := r.Find(http.MethodGet, path, ctx); err == nil {
+			t.Errorf("%s: expected no match", path)
+		}
+	}
+
+	if _, err := ctx.PathParamInt("id"); err == nil {
+		t.Error("Expected PathParamInt to fail for a missing param")
+	}
+}
+
+func TestTrieRouter_ConstraintConflicts(t *testing.T) {
+	r := NewTrieRouter()
+	handler := func(c *amaro.Context) error { return nil }
+
+	if err := r.GET("/items/{id:int}", handler); err != nil {
+		t.Fatal(err)
+	}
+	// Same constraint, same name reuses the node
+	if err := r.GET("/items/{id:int}/tags", handler); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	// Same constraint, different name conflicts
+	if err := r.GET("/items/{item:int}/owner", handler); err == nil {
+		t.Error("Expected param name conflict")
+	}
+	// Invalid regex is reported
+	if err := r.GET("/bad/{id:[0-9}", handler); err == nil {
+		t.Error("Expected invalid constraint error")
+	}
+}
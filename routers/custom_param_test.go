@@ -2,6 +2,7 @@ package routers
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/buildwithgo/amaro"
@@ -26,3 +27,108 @@ func TestTrieRouter_CustomParamSyntax(t *testing.T) {
 	if err != nil { t.Fatal(err) }
 	if ctx.PathParam("name") != "john" { t.Errorf("Expected john, got %s", ctx.PathParam("name")) }
 }
+
+func TestTrieRouter_OptionalParams(t *testing.T) {
+	r := NewTrieRouter()
+	r.GET("/posts/:page?", func(c *amaro.Context) error { return nil })
+	r.GET("/archive/{year}/{month}?", func(c *amaro.Context) error { return nil })
+
+	ctx := amaro.NewContext(nil, nil)
+
+	route, err := r.Find(http.MethodGet, "/posts", ctx)
+	if err != nil { t.Fatal(err) }
+	if route.Path != "/posts/:page?" { t.Errorf("Expected pattern /posts/:page?, got %s", route.Path) }
+	if len(ctx.Params) != 0 { t.Errorf("Expected no params, got %v", ctx.Params) }
+
+	ctx.Reset(nil, nil)
+	_, err = r.Find(http.MethodGet, "/posts/3", ctx)
+	if err != nil { t.Fatal(err) }
+	if ctx.PathParam("page") != "3" { t.Errorf("Expected page=3, got %s", ctx.PathParam("page")) }
+
+	ctx.Reset(nil, nil)
+	_, err = r.Find(http.MethodGet, "/archive/2024", ctx)
+	if err != nil { t.Fatal(err) }
+	if ctx.PathParam("year") != "2024" { t.Errorf("Expected year=2024, got %s", ctx.PathParam("year")) }
+
+	// Routes() lists each registration once with its original pattern
+	routes := r.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d: %+v", len(routes), routes)
+	}
+
+	// Optional params must be trailing
+	if err := r.GET("/bad/:opt?/tail", func(c *amaro.Context) error { return nil }); err == nil {
+		t.Error("Expected error for non-trailing optional param")
+	}
+}
+
+func TestTrieRouter_MultiParamSegments(t *testing.T) {
+	r := NewTrieRouter()
+	r.GET("/files/:name.:ext", func(c *amaro.Context) error { return nil })
+	r.GET("/geo/{lat},{lng}", func(c *amaro.Context) error { return nil })
+	r.GET("/api/v{version:int}/status", func(c *amaro.Context) error { return nil })
+	r.GET("/files/:id", func(c *amaro.Context) error { return nil })
+
+	cases := []struct {
+		path   string
+		params map[string]string
+	}{
+		{"/files/archive.tar.gz", map[string]string{"name": "archive.tar", "ext": "gz"}},
+		{"/geo/51.5,-0.12", map[string]string{"lat": "51.5", "lng": "-0.12"}},
+		{"/api/v2/status", map[string]string{"version": "2"}},
+		// No separator, falls back to the plain param
+		{"/files/README", map[string]string{"id": "README"}},
+	}
+
+	ctx := amaro.NewContext(nil, nil)
+	for _, tc := range cases {
+		ctx.Reset(nil, nil)
+		if _, err := r.Find(http.MethodGet, tc.path, ctx); err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+			continue
+		}
+		if len(ctx.Params) != len(tc.params) {
+			t.Errorf("%s: unexpected params %v", tc.path, ctx.Params)
+		}
+		for k, v := range tc.params {
+			if got := ctx.PathParam(k); got != v {
+				t.Errorf("%s: expected %s=%q, got %q", tc.path, k, v, got)
+			}
+		}
+	}
+
+	ctx.Reset(nil, nil)
+	if _, err := r.Find(http.MethodGet, "/api/vX/status", ctx); err == nil {
+		t.Error("Expected constraint to reject /api/vX/status")
+	}
+
+	if err := r.GET("/bad/:a:b", func(c *amaro.Context) error { return nil }); err != nil {
+		t.Errorf("Expected :a:b to be a constrained param, got %v", err)
+	}
+	if err := r.GET("/bad/{a}{b}", func(c *amaro.Context) error { return nil }); err == nil {
+		t.Error("Expected error for adjacent params")
+	}
+}
+
+func TestTrieRouter_ColonInStaticSegment(t *testing.T) {
+	r := NewTrieRouter()
+	if err := r.POST("/v1/items:search", func(c *amaro.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	route, err := r.Find(http.MethodPost, "/v1/items:search", nil)
+	if err != nil || route.Path != "/v1/items:search" {
+		t.Fatalf("Expected /v1/items:search to match, got %v, %v", route, err)
+	}
+	for _, path := range []string{"/v1/items:delete", "/v1/items:anything"} {
+		if _, err := r.Find(http.MethodPost, path, nil); err == nil {
+			t.Errorf("%s: expected no match for a static :verb route", path)
+		}
+	}
+
+	app := amaro.New(amaro.WithRouter(NewTrieRouter()))
+	app.POST("/v1/items:search", func(c *amaro.Context) error { return nil })
+	if w := app.Test(httptest.NewRequest(http.MethodPost, "/v1/items:delete", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for /v1/items:delete, got %d", w.Code)
+	}
+}
@@ -0,0 +1,181 @@
+package routers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// hostLabel is a parsed label of a host pattern.
+type hostLabel struct {
+	text       string // lowercased static label
+	name       string // param or wildcard name
+	constraint amaro.Constraint
+	param      bool
+	wildcard   bool
+}
+
+// hostRouter is the router serving the routes scoped to a host pattern.
+type hostRouter struct {
+	pattern string
+	labels  []hostLabel
+	static  bool
+	router  amaro.Router
+}
+
+// hosts dispatches requests to routers scoped by host pattern.
+// Fully static patterns are tried before patterns with params, otherwise in registration order.
+type hosts struct {
+	routers []*hostRouter
+}
+
+// parseHost splits pattern into labels using the parsers of config.
+// Params match a single label; a wildcard may only be the first label and matches one or more labels.
+func parseHost(config amaro.RouterConfig, pattern string) ([]hostLabel, error) {
+	parts := strings.Split(strings.TrimSuffix(pattern, "."), ".")
+	labels := make([]hostLabel, len(parts))
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("empty label in host %s", pattern)
+		}
+
+		isParam, paramName := false, ""
+		if config.ParamParser != nil {
+			isParam, paramName = config.ParamParser(part)
+		}
+		isWildcard, wildcardName := false, ""
+		if !isParam && config.WildcardParser != nil {
+			isWildcard, wildcardName = config.WildcardParser(part)
+		}
+
+		switch {
+		case isParam:
+			name, _, constraint, err := compileParam(config, paramName)
+			if err != nil {
+				return nil, err
+			}
+			labels[i] = hostLabel{name: name, constraint: constraint, param: true}
+		case isWildcard:
+			if i != 0 {
+				return nil, fmt.Errorf("wildcard must be the first label in host %s", pattern)
+			}
+			labels[i] = hostLabel{name: wildcardName, wildcard: true}
+		default:
+			labels[i] = hostLabel{text: strings.ToLower(part)}
+		}
+	}
+	return labels, nil
+}
+
+// add returns the router for pattern, creating it with newRouter if needed.
+// If pattern is invalid, the error is returned with a router that is never dispatched to.
+func (h *hosts) add(config amaro.RouterConfig, pattern string, newRouter func() amaro.Router) (amaro.Router, error) {
+	for _, hr := range h.routers {
+		if hr.pattern == pattern {
+			return hr.router, nil
+		}
+	}
+
+	labels, err := parseHost(config, pattern)
+	if err != nil {
+		return newRouter(), fmt.Errorf("host %s: %w", pattern, err)
+	}
+
+	hr := &hostRouter{pattern: pattern, labels: labels, static: true, router: newRouter()}
+	for _, l := range labels {
+		if l.param || l.wildcard {
+			hr.static = false
+		}
+	}
+
+	i := len(h.routers)
+	if hr.static {
+		// Insert after the existing static patterns
+		i = 0
+		for i < len(h.routers) && h.routers[i].static {
+			i++
+		}
+	}
+	h.routers = append(h.routers, nil)
+	copy(h.routers[i+1:], h.routers[i:])
+	h.routers[i] = hr
+	return hr.router, nil
+}
+
+// find looks up the route in the router of the first pattern matching the request host,
+// adding the host params to ctx. It reports false if no pattern matches; the request is
+// then left to the routes registered without a host.
+func (h *hosts) find(method, path string, ctx *amaro.Context) (*amaro.Route, bool, error) {
+	if len(h.routers) == 0 || ctx == nil || ctx.Request == nil {
+		return nil, false, nil
+	}
+
+	host := ctx.Request.Host
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	for _, hr := range h.routers {
+		mark := len(ctx.Params)
+		if !hr.match(host, ctx) {
+			ctx.Params = ctx.Params[:mark]
+			continue
+		}
+		route, err := hr.router.Find(method, path, ctx)
+		if err != nil {
+			ctx.Params = ctx.Params[:mark]
+		}
+		return route, true, err
+	}
+	return nil, false, nil
+}
+
+// match matches host against the labels from right to left, adding params to ctx.
+func (hr *hostRouter) match(host string, ctx *amaro.Context) bool {
+	for i := len(hr.labels) - 1; i >= 0; i-- {
+		l := hr.labels[i]
+		if l.wildcard {
+			if host == "" {
+				return false
+			}
+			if l.name != "" {
+				ctx.AddParam(l.name, host)
+			}
+			return true
+		}
+
+		label := host
+		if j := strings.LastIndexByte(host, '.'); j >= 0 {
+			label, host = host[j+1:], host[:j]
+		} else {
+			host = ""
+		}
+		if label == "" {
+			return false
+		}
+
+		switch {
+		case l.param:
+			if l.constraint != nil && !l.constraint(label) {
+				return false
+			}
+			ctx.AddParam(l.name, label)
+		case !strings.EqualFold(label, l.text):
+			return false
+		}
+	}
+	// Nothing may be left once the first label is matched
+	return host == ""
+}
+
+// routes returns the routes of all host routers.
+func (h *hosts) routes() []amaro.Route {
+	var routes []amaro.Route
+	for _, hr := range h.routers {
+		routes = append(routes, hr.router.Routes()...)
+	}
+	return routes
+}
@@ -54,3 +54,27 @@ func TestRoutesIntrospection(t *testing.T) {
 		}
 	}
 }
+
+func TestRoutesIntrospection_Names(t *testing.T) {
+	r := routers.NewTrieRouter()
+
+	r.AddRoute(amaro.Route{
+		Method:  "GET",
+		Path:    "/users/:id",
+		Name:    "user.show",
+		Handler: func(c *amaro.Context) error { return nil },
+	})
+	r.GET("/users", func(c *amaro.Context) error { return nil })
+
+	names := map[string]string{}
+	for _, route := range r.Routes() {
+		names[route.Path] = route.Name
+	}
+
+	if names["/users/:id"] != "user.show" {
+		t.Errorf("Expected name user.show, got %q", names["/users/:id"])
+	}
+	if names["/users"] != "" {
+		t.Errorf("Expected unnamed /users, got %q", names["/users"])
+	}
+}
@@ -0,0 +1,145 @@
+package routers
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// lookupFunc finds the route of path, ignoring empty segments and a trailing slash.
+type lookupFunc func(method, path string, ctx *amaro.Context) (*amaro.Route, error)
+
+// foldFunc is a lookupFunc comparing static segments case-insensitively.
+// It returns the path with the registered casing, or a nil route.
+type foldFunc func(method, path string, ctx *amaro.Context) (*amaro.Route, string)
+
+// findCanonical finds the route of reqPath, applying the path policies of config.
+// The canonical path of a route is clean, uses its registered casing and ends with a
+// slash only if the route does. When a PathRedirect policy applies, the returned route
+// redirects to the path with every difference fixed. fold may be nil if the router cannot fold case.
+func findCanonical(config amaro.RouterConfig, method, reqPath string, ctx *amaro.Context, lookup lookupFunc, fold foldFunc) (*amaro.Route, error) {
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	redirect := false
+
+	if !isClean(reqPath) {
+		if config.CleanPath == amaro.PathStrict {
+			return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+		}
+		reqPath = cleanPath(reqPath)
+		redirect = config.CleanPath == amaro.PathRedirect
+	}
+
+	var mark int
+	if ctx != nil {
+		mark = len(ctx.Params)
+	}
+
+	route, err := lookup(method, reqPath, ctx)
+	if err != nil && config.Case != amaro.PathStrict && fold != nil {
+		if folded, fixed := fold(method, reqPath, ctx); folded != nil {
+			if hasSlash(reqPath) && !hasSlash(fixed) {
+				fixed += "/"
+			}
+			route, err, reqPath = folded, nil, fixed
+			redirect = redirect || config.Case == amaro.PathRedirect
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reqPath != "/" && hasSlash(reqPath) != hasSlash(route.Path) && !endsWithWildcard(config, route.Path) {
+		switch config.TrailingSlash {
+		case amaro.PathStrict:
+			if ctx != nil {
+				ctx.Params = ctx.Params[:mark]
+			}
+			return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+		case amaro.PathRedirect:
+			if hasSlash(reqPath) {
+				reqPath = reqPath[:len(reqPath)-1]
+			} else {
+				reqPath += "/"
+			}
+			redirect = true
+		}
+	}
+
+	if redirect {
+		return redirectRoute(method, reqPath), nil
+	}
+	return route, nil
+}
+
+// redirectRoute returns a route redirecting to location, keeping the query string.
+func redirectRoute(method, location string) *amaro.Route {
+	code := http.StatusPermanentRedirect
+	if method == http.MethodGet || method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	escaped := (&url.URL{Path: location}).EscapedPath()
+
+	return &amaro.Route{
+		Method: method,
+		Path:   location,
+		Handler: func(c *amaro.Context) error {
+			target := escaped
+			if c.Request != nil && c.Request.URL.RawQuery != "" {
+				target += "?" + c.Request.URL.RawQuery
+			}
+			return c.Redirect(code, target)
+		},
+	}
+}
+
+// isClean reports whether p is rooted and has no empty, "." or ".." segments.
+// A trailing slash is allowed.
+func isClean(p string) bool {
+	if p == "" || p[0] != '/' {
+		return false
+	}
+	start := 1
+	for i := 1; i <= len(p); i++ {
+		if i < len(p) && p[i] != '/' {
+			continue
+		}
+		switch p[start:i] {
+		case ".", "..":
+			return false
+		case "":
+			// Only the last segment may be empty
+			if i < len(p) {
+				return false
+			}
+		}
+		start = i + 1
+	}
+	return true
+}
+
+// cleanPath returns the canonical form of p, keeping its trailing slash.
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if cleaned != "/" && hasSlash(p) {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+func hasSlash(p string) bool {
+	return len(p) > 1 && p[len(p)-1] == '/'
+}
+
+// endsWithWildcard reports whether the last segment of pattern is a wildcard.
+func endsWithWildcard(config amaro.RouterConfig, pattern string) bool {
+	if config.WildcardParser == nil {
+		return false
+	}
+	isWildcard, _ := config.WildcardParser(pattern[strings.LastIndexByte(pattern, '/')+1:])
+	return isWildcard
+}
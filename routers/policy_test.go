@@ -0,0 +1,156 @@
+package routers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestPathPolicies(t *testing.T) {
+	newRouters := map[string]func(amaro.RouterConfig) amaro.Router{
+		"Trie":  func(c amaro.RouterConfig) amaro.Router { return routers.NewTrieRouter(routers.WithConfig(c)) },
+		"Radix": func(c amaro.RouterConfig) amaro.Router { return routers.NewRadixRouter(routers.WithRadixConfig(c)) },
+	}
+
+	type request struct {
+		method   string
+		path     string
+		code     int
+		location string
+	}
+	policies := []struct {
+		name      string
+		configure func(*amaro.RouterConfig)
+		requests  []request
+	}{
+		{
+			name:      "Default",
+			configure: func(*amaro.RouterConfig) {},
+			requests: []request{
+				{http.MethodGet, "/users/", http.StatusOK, ""},
+				{http.MethodGet, "/docs", http.StatusOK, ""},
+				{http.MethodGet, "/users//42", http.StatusOK, ""},
+				{http.MethodGet, "/docs/../users/./42", http.StatusOK, ""},
+				{http.MethodGet, "/USERS", http.StatusNotFound, ""},
+			},
+		},
+		{
+			name: "Strict",
+			configure: func(c *amaro.RouterConfig) {
+				c.TrailingSlash = amaro.PathStrict
+				c.CleanPath = amaro.PathStrict
+			},
+			requests: []request{
+				{http.MethodGet, "/users", http.StatusOK, ""},
+				{http.MethodGet, "/users/", http.StatusNotFound, ""},
+				{http.MethodGet, "/docs/", http.StatusOK, ""},
+				{http.MethodGet, "/docs", http.StatusNotFound, ""},
+				{http.MethodGet, "/users//42", http.StatusNotFound, ""},
+				// Wildcards accept both forms
+				{http.MethodGet, "/Files/a/", http.StatusOK, ""},
+			},
+		},
+		{
+			name: "Redirect",
+			configure: func(c *amaro.RouterConfig) {
+				c.TrailingSlash = amaro.PathRedirect
+				c.CleanPath = amaro.PathRedirect
+				c.Case = amaro.PathRedirect
+			},
+			requests: []request{
+				{http.MethodGet, "/users", http.StatusOK, ""},
+				{http.MethodGet, "/users/", http.StatusMovedPermanently, "/users"},
+				{http.MethodGet, "/docs", http.StatusMovedPermanently, "/docs/"},
+				{http.MethodPost, "/users/", http.StatusPermanentRedirect, "/users"},
+				{http.MethodGet, "/users//42?tab=1", http.StatusMovedPermanently, "/users/42?tab=1"},
+				{http.MethodGet, "/USERS/42/", http.StatusMovedPermanently, "/users/42"},
+				{http.MethodGet, "/about", http.StatusMovedPermanently, "/About"},
+				{http.MethodGet, "/files/Img/Logo.png", http.StatusMovedPermanently, "/Files/Img/Logo.png"},
+				{http.MethodGet, "/nope", http.StatusNotFound, ""},
+			},
+		},
+		{
+			name: "CaseInsensitive",
+			configure: func(c *amaro.RouterConfig) {
+				c.Case = amaro.PathLenient
+			},
+			requests: []request{
+				{http.MethodGet, "/ABOUT", http.StatusOK, ""},
+				{http.MethodGet, "/Users/42", http.StatusOK, ""},
+			},
+		},
+	}
+
+	handler := func(c *amaro.Context) error {
+		return c.String(http.StatusOK, c.PathParam("id"))
+	}
+
+	for name, newRouter := range newRouters {
+		for _, policy := range policies {
+			t.Run(name+"/"+policy.name, func(t *testing.T) {
+				config := amaro.DefaultRouterConfig()
+				policy.configure(&config)
+
+				app := amaro.New(amaro.WithRouter(newRouter(config)))
+				app.GET("/users", handler)
+				app.POST("/users", handler)
+				app.GET("/users/:id", handler)
+				app.GET("/docs/", handler)
+				app.GET("/About", handler)
+				app.GET("/Files/*path", handler)
+
+				for _, req := range policy.requests {
+					w := app.Test(httptest.NewRequest(req.method, req.path, nil))
+					if w.Code != req.code {
+						t.Errorf("%s %s: expected %d, got %d", req.method, req.path, req.code, w.Code)
+					}
+					if got := w.Header().Get("Location"); got != req.location {
+						t.Errorf("%s %s: expected location %q, got %q", req.method, req.path, req.location, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCleanPathLenient(t *testing.T) {
+	// PathLenient is the default
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	// A middleware guarding a prefix must see the path that is routed
+	app.Use(func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			if strings.HasPrefix(c.Request.URL.Path, "/admin") {
+				return amaro.NewHTTPError(http.StatusForbidden)
+			}
+			return next(c)
+		}
+	})
+	app.GET("/admin", func(c *amaro.Context) error { return c.String(http.StatusOK, "secret") })
+	app.GET("/public/*path", func(c *amaro.Context) error { return c.String(http.StatusOK, c.Request.URL.Path) })
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/public/../admin", http.StatusForbidden, ""},
+		{"/public//css/./app.css", http.StatusOK, "/public/css/app.css"},
+		{"/public/docs/", http.StatusOK, "/public/docs/"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = tt.path
+		w := app.Test(req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.code, w.Code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+}
@@ -74,3 +74,61 @@ func TestTrieRouter_ConflictDetection(t *testing.T) {
 		t.Error("Expected error for conflicting wildcard name, got nil")
 	}
 }
+
+func TestTrieRouter_Backtracking(t *testing.T) {
+	r := NewTrieRouter()
+
+	r.GET("/users/new", func(c *amaro.Context) error { return fmt.Errorf("new") })
+	r.GET("/users/:id/edit", func(c *amaro.Context) error { return fmt.Errorf("edit") })
+	r.GET("/files/:id/meta", func(c *amaro.Context) error { return fmt.Errorf("meta") })
+	r.GET("/files/*path", func(c *amaro.Context) error { return fmt.Errorf("wildcard") })
+	r.GET("/a/b/c", func(c *amaro.Context) error { return fmt.Errorf("abc") })
+	r.GET("/a/:x/:y/d", func(c *amaro.Context) error { return fmt.Errorf("axyd") })
+
+	cases := []struct {
+		path   string
+		want   string
+		params map[string]string
+	}{
+		// Static /users/new has no /edit child, fall back to the param
+		{"/users/new/edit", "edit", map[string]string{"id": "new"}},
+		{"/users/new", "new", nil},
+		// Param branch fails deeper, fall back to the wildcard
+		{"/files/1/other", "wildcard", map[string]string{"path": "1/other"}},
+		{"/files/1/meta", "meta", map[string]string{"id": "1"}},
+		// Static branch fails two levels down
+		{"/a/b/c/d", "axyd", map[string]string{"x": "b", "y": "c"}},
+		{"/a/b/c", "abc", nil},
+	}
+
+	ctx := amaro.NewContext(nil, nil)
+	for _, tc := range cases {
+		ctx.Reset(nil, nil)
+		route, err := r.Find(http.MethodGet, tc.path, ctx)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+			continue
+		}
+		if err := route.Handler(ctx); err == nil || err.Error() != tc.want {
+			t.Errorf("%s: expected %s handler, got %v", tc.path, tc.want, err)
+		}
+		for k, v := range tc.params {
+			if got := ctx.PathParam(k); got != v {
+				t.Errorf("%s: expected %s=%q, got %q", tc.path, k, v, got)
+			}
+		}
+		// Params from failed branches must be discarded
+		if len(ctx.Params) != len(tc.params) {
+			t.Errorf("%s: unexpected params %v", tc.path, ctx.Params)
+		}
+	}
+
+	// Backtracking stays allocation-free
+	allocs := testing.AllocsPerRun(100, func() {
+		ctx.Reset(nil, nil)
+		_, _ = r.Find(http.MethodGet, "/a/b/c/d", ctx)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected zero allocations, got %v", allocs)
+	}
+}
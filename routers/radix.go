@@ -0,0 +1,595 @@
+package routers
+
+import (
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/buildwithgo/amaro"
+)
+
+type radixNode struct {
+	// prefix is the static text this node consumes.
+	prefix string
+
+	// Static children, indices holds the first byte of each child prefix
+	indices  string
+	children []*radixNode
+
+	// Dynamic children, only present on nodes ending at a segment boundary
+	patterns []*patternEdge[*radixNode]
+	params   []*paramEdge[*radixNode]
+
+	catchAllNode *radixNode
+	catchAllName string
+
+	route *amaro.Route
+
+	// implicit is set when the route is served here because its trailing params are optional
+	implicit bool
+}
+
+func newRadixNode() *radixNode {
+	return &radixNode{}
+}
+
+// child returns the static child whose prefix starts with c.
+func (n *radixNode) child(c byte) *radixNode {
+	for i := 0; i < len(n.indices); i++ {
+		if n.indices[i] == c {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// dynamic reports whether n has param, multi-param or wildcard children.
+func (n *radixNode) dynamic() bool {
+	return len(n.patterns) > 0 || len(n.params) > 0 || n.catchAllNode != nil
+}
+
+// insert walks or creates the static path s below n, splitting nodes on partial
+// matches, and returns the node where s ends.
+func (n *radixNode) insert(s string) *radixNode {
+	for s != "" {
+		child := n.child(s[0])
+		if child == nil {
+			child = &radixNode{prefix: s}
+			n.indices += string(s[0])
+			n.children = append(n.children, child)
+			return child
+		}
+
+		common := 0
+		for common < len(s) && common < len(child.prefix) && s[common] == child.prefix[common] {
+			common++
+		}
+
+		if common < len(child.prefix) {
+			// Split child: it keeps the common prefix, the rest moves to a new node
+			rest := *child
+			rest.prefix = child.prefix[common:]
+			*child = radixNode{
+				prefix:   child.prefix[:common],
+				indices:  string(rest.prefix[0]),
+				children: []*radixNode{&rest},
+			}
+		}
+
+		n = child
+		s = s[common:]
+	}
+	return n
+}
+
+// RadixRouter is a compressed radix (Patricia) tree router.
+// Static path text is stored in shared prefixes and matched byte by byte,
+// while params, multi-param segments and wildcards hang off segment boundaries.
+// It supports the same syntax and RouterConfig as TrieRouter.
+type RadixRouter struct {
+	root              map[string]*radixNode // method -> root node
+	globalMiddlewares []amaro.Middleware
+	config            amaro.RouterConfig
+	hosts             hosts
+	host              string       // host pattern of a router created by Host
+	parent            *RadixRouter // router that created it by Host
+	registrations     registrations
+}
+
+// RadixRouterOption configures RadixRouter.
+type RadixRouterOption func(*RadixRouter)
+
+// WithRadixConfig sets the router configuration.
+func WithRadixConfig(config amaro.RouterConfig) RadixRouterOption {
+	return func(r *RadixRouter) {
+		r.config = config
+	}
+}
+
+// NewRadixRouter creates a new instance of RadixRouter.
+func NewRadixRouter(opts ...RadixRouterOption) *RadixRouter {
+	r := &RadixRouter{
+		root:   make(map[string]*radixNode),
+		config: amaro.DefaultRouterConfig(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Use adds a global middleware to the router.
+// Note: These middlewares are applied to all routes registered AFTER calling Use.
+func (r *RadixRouter) Use(middleware amaro.Middleware) {
+	r.globalMiddlewares = append(r.globalMiddlewares, middleware)
+}
+
+// Config returns the router configuration.
+func (r *RadixRouter) Config() amaro.RouterConfig {
+	return r.config
+}
+
+func (r *RadixRouter) Add(method, path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.AddRoute(amaro.Route{
+		Method:      method,
+		Path:        path,
+		Handler:     handler,
+		Middlewares: middlewares,
+	})
+}
+
+// AddRoute registers a fully described route.
+// Failed registrations are also recorded and reported by Validate.
+func (r *RadixRouter) AddRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
+	}
+	return r.registrations.record(route, r.addRoute(route, false))
+}
+
+// ReplaceRoute registers route, replacing the route registered for its method and path.
+func (r *RadixRouter) ReplaceRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
+	}
+	return r.registrations.record(route, r.addRoute(route, true))
+}
+
+// Version returns the number of registrations, including those of host routers.
+func (r *RadixRouter) Version() uint64 {
+	return r.registrations.version(&r.hosts)
+}
+
+// Validate returns the errors of all failed registrations, including those of host routers.
+func (r *RadixRouter) Validate() error {
+	return r.registrations.validate(&r.hosts)
+}
+
+// addRoute registers route. An existing route for the same path is an error unless replace is set.
+func (r *RadixRouter) addRoute(route amaro.Route, replace bool) error {
+	segments, err := parseSegments(r.config, route.Path)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := r.root[route.Method]; !ok {
+		r.root[route.Method] = newRadixNode()
+	}
+	n := r.root[route.Method]
+
+	// Nodes preceding optional params also serve the route
+	var implicit []*radixNode
+
+	// Static text is buffered until the next dynamic segment
+	pending := "/"
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		if seg.kind == staticSegment {
+			pending += seg.text
+			if !last {
+				pending += "/"
+			}
+			continue
+		}
+
+		n = n.insert(pending)
+		pending = ""
+		if seg.optional {
+			implicit = append(implicit, n)
+		}
+
+		switch seg.kind {
+		case patternSegment:
+			n = addPatternEdge(&n.patterns, seg, newRadixNode)
+		case paramSegment:
+			child, err := addParamEdge(&n.params, seg, newRadixNode)
+			if err != nil {
+				return err
+			}
+			n = child
+		case wildcardSegment:
+			if n.catchAllNode == nil {
+				n.catchAllNode = newRadixNode()
+				n.catchAllName = seg.name
+			}
+			if n.catchAllName != seg.name {
+				return conflict("wildcard name %s vs %s", n.catchAllName, seg.name)
+			}
+			n = n.catchAllNode
+		}
+
+		if !last {
+			pending = "/"
+		}
+	}
+	n = n.insert(pending)
+	if n.route != nil && !n.implicit && !replace {
+		return conflict("already registered as %s", n.route.Path)
+	}
+
+	compiled := compileRoute(route, r.middlewares())
+	n.route = &compiled
+	n.implicit = false
+
+	for _, in := range implicit {
+		// Explicit registrations take precedence
+		if in.route != nil && !in.implicit {
+			continue
+		}
+		in.route = n.route
+		in.implicit = true
+	}
+
+	return nil
+}
+
+func (r *RadixRouter) Find(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	if route, ok, err := r.hosts.find(method, path, ctx); ok {
+		return route, err
+	}
+	return findCanonical(r.config, method, path, ctx, r.lookup, r.lookupFold)
+}
+
+// lookup finds the route of path, ignoring a trailing slash.
+func (r *RadixRouter) lookup(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	if root, ok := r.root[method]; ok {
+		if route := root.find(path, ctx); route != nil {
+			return route, nil
+		}
+	}
+
+	// The path may still be registered under other methods
+	return nil, notFound(r.root, method, func(n *radixNode) bool {
+		return n.find(path, nil) != nil
+	})
+}
+
+// lookupFold is lookup with static text compared case-insensitively.
+func (r *RadixRouter) lookupFold(method, path string, ctx *amaro.Context) (*amaro.Route, string) {
+	root, ok := r.root[method]
+	if !ok {
+		return nil, ""
+	}
+	route, fixed := root.findFold(path, ctx, make([]byte, 0, len(path)+1))
+	if route == nil {
+		return nil, ""
+	}
+	if len(fixed) == 0 {
+		return route, "/"
+	}
+	return route, string(fixed)
+}
+
+// consume strips prefix from path. A prefix ending in a slash also matches a path
+// missing that final slash, so "/static" reaches the children of "/static/".
+func consume(prefix, path string) (string, bool) {
+	if len(path) >= len(prefix) && path[:len(prefix)] == prefix {
+		return path[len(prefix):], true
+	}
+	if len(path)+1 == len(prefix) && prefix[len(prefix)-1] == '/' && path == prefix[:len(path)] {
+		return "", true
+	}
+	return "", false
+}
+
+// find matches path, the remainder after the prefix of n, against the subtree rooted at n.
+// Priority is Static > Multi-param > Param > Wildcard; when a deeper lookup fails it
+// backtracks to the next candidate, discarding params added by the failed branch.
+// A single trailing slash is ignored. It returns nil if no route matches.
+func (n *radixNode) find(path string, ctx *amaro.Context) *amaro.Route {
+	if path == "" {
+		if n.route != nil {
+			return n.route
+		}
+		if n.catchAllNode != nil && n.catchAllNode.route != nil {
+			if ctx != nil {
+				ctx.AddParam(n.catchAllName, "")
+			}
+			return n.catchAllNode.route
+		}
+		// The route may continue with a slash, e.g. "/static" for "/static/*filepath"
+		if child := n.child('/'); child != nil && child.prefix == "/" {
+			return child.find("", ctx)
+		}
+		return nil
+	}
+
+	// 1. Static
+	if child := n.child(path[0]); child != nil {
+		if rest, ok := consume(child.prefix, path); ok {
+			if route := child.find(rest, ctx); route != nil {
+				return route
+			}
+		}
+	}
+
+	if n.dynamic() {
+		part, rest := path, ""
+		if i := strings.IndexByte(path, '/'); i >= 0 {
+			part, rest = path[:i], path[i:]
+		}
+
+		if part != "" {
+			// 2. Multi-param segments
+			for _, p := range n.patterns {
+				var mark int
+				if ctx != nil {
+					mark = len(ctx.Params)
+				}
+				if matchParts(p.parts, part, ctx) {
+					if route := p.node.find(rest, ctx); route != nil {
+						return route
+					}
+				}
+				if ctx != nil {
+					ctx.Params = ctx.Params[:mark]
+				}
+			}
+
+			// 3. Param, constrained first
+			for _, p := range n.params {
+				if p.constraint != nil && !p.constraint(part) {
+					continue
+				}
+				var mark int
+				if ctx != nil {
+					mark = len(ctx.Params)
+					ctx.AddParam(p.name, part)
+				}
+				if route := p.node.find(rest, ctx); route != nil {
+					return route
+				}
+				if ctx != nil {
+					ctx.Params = ctx.Params[:mark]
+				}
+			}
+		}
+
+		// 4. CatchAll
+		if n.catchAllNode != nil && n.catchAllNode.route != nil {
+			value := path
+			if len(value) > 0 && value[len(value)-1] == '/' {
+				value = value[:len(value)-1]
+			}
+			if ctx != nil {
+				ctx.AddParam(n.catchAllName, value)
+			}
+			return n.catchAllNode.route
+		}
+	}
+
+	// Trailing slash
+	if path == "/" {
+		return n.find("", ctx)
+	}
+	return nil
+}
+
+// consumeFold is consume comparing case-insensitively. It also returns the length of prefix consumed.
+func consumeFold(prefix, path string) (string, int, bool) {
+	if len(path) >= len(prefix) && strings.EqualFold(path[:len(prefix)], prefix) {
+		return path[len(prefix):], len(prefix), true
+	}
+	if len(path)+1 == len(prefix) && prefix[len(prefix)-1] == '/' && strings.EqualFold(path, prefix[:len(path)]) {
+		return "", len(path), true
+	}
+	return "", 0, false
+}
+
+// findFold is find with static text compared case-insensitively.
+// It also returns fixed extended with the matched path in the registered casing.
+func (n *radixNode) findFold(path string, ctx *amaro.Context, fixed []byte) (*amaro.Route, []byte) {
+	if path == "" {
+		if n.route != nil {
+			return n.route, fixed
+		}
+		if n.catchAllNode != nil && n.catchAllNode.route != nil {
+			if ctx != nil {
+				ctx.AddParam(n.catchAllName, "")
+			}
+			return n.catchAllNode.route, fixed
+		}
+		if child := n.child('/'); child != nil && child.prefix == "/" {
+			return child.findFold("", ctx, fixed)
+		}
+		return nil, fixed
+	}
+
+	mark := len(fixed)
+	var paramMark int
+	if ctx != nil {
+		paramMark = len(ctx.Params)
+	}
+
+	// 1. Static, in any casing
+	for _, child := range n.children {
+		rest, consumed, ok := consumeFold(child.prefix, path)
+		if !ok {
+			continue
+		}
+		if route, f := child.findFold(rest, ctx, append(fixed[:mark], child.prefix[:consumed]...)); route != nil {
+			return route, f
+		}
+	}
+
+	if n.dynamic() {
+		part, rest := path, ""
+		if i := strings.IndexByte(path, '/'); i >= 0 {
+			part, rest = path[:i], path[i:]
+		}
+
+		if part != "" {
+			// 2. Multi-param segments
+			for _, p := range n.patterns {
+				if matchParts(p.parts, part, ctx) {
+					if route, f := p.node.findFold(rest, ctx, append(fixed[:mark], part...)); route != nil {
+						return route, f
+					}
+				}
+				if ctx != nil {
+					ctx.Params = ctx.Params[:paramMark]
+				}
+			}
+
+			// 3. Param, constrained first
+			for _, p := range n.params {
+				if p.constraint != nil && !p.constraint(part) {
+					continue
+				}
+				if ctx != nil {
+					ctx.AddParam(p.name, part)
+				}
+				if route, f := p.node.findFold(rest, ctx, append(fixed[:mark], part...)); route != nil {
+					return route, f
+				}
+				if ctx != nil {
+					ctx.Params = ctx.Params[:paramMark]
+				}
+			}
+		}
+
+		// 4. CatchAll
+		if n.catchAllNode != nil && n.catchAllNode.route != nil {
+			value := path
+			if len(value) > 0 && value[len(value)-1] == '/' {
+				value = value[:len(value)-1]
+			}
+			if ctx != nil {
+				ctx.AddParam(n.catchAllName, value)
+			}
+			return n.catchAllNode.route, append(fixed[:mark], value...)
+		}
+	}
+
+	// Trailing slash
+	if path == "/" {
+		return n.findFold("", ctx, fixed[:mark])
+	}
+	return nil, fixed[:mark]
+}
+
+func (r *RadixRouter) Routes() []amaro.Route {
+	var routes []amaro.Route
+
+	// Sort methods for deterministic output
+	for _, method := range sortedMethods(r.root) {
+		walkRadixNode(r.root[method], &routes)
+	}
+	return append(routes, r.hosts.routes()...)
+}
+
+func walkRadixNode(n *radixNode, routes *[]amaro.Route) {
+	if n == nil {
+		return
+	}
+	if n.route != nil && !n.implicit {
+		*routes = append(*routes, *n.route)
+	}
+
+	// Walk static children (sorted for determinism)
+	children := make([]*radixNode, len(n.children))
+	copy(children, n.children)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].prefix < children[j].prefix
+	})
+	for _, child := range children {
+		walkRadixNode(child, routes)
+	}
+
+	// Walk multi-param segments and params
+	for _, p := range n.patterns {
+		walkRadixNode(p.node, routes)
+	}
+	for _, p := range n.params {
+		walkRadixNode(p.node, routes)
+	}
+
+	// Walk wildcard
+	walkRadixNode(n.catchAllNode, routes)
+}
+
+func (r *RadixRouter) StaticFS(pathPrefix string, fsys fs.FS) {
+	handler := amaro.StaticHandler(amaro.StaticConfig{
+		Root:   fsys,
+		Prefix: pathPrefix,
+	})
+
+	path := strings.TrimRight(pathPrefix, "/")
+	r.Add(http.MethodGet, path, handler)
+	r.Add(http.MethodHead, path, handler)
+
+	wildcardPath := path + "/*filepath"
+	r.Add(http.MethodGet, wildcardPath, handler)
+	r.Add(http.MethodHead, wildcardPath, handler)
+}
+
+// Host returns a group whose routes only match requests for hosts matching pattern,
+// e.g. "api.example.com" or "{tenant}.example.com". Params match a single label and are
+// read through Context.PathParam; a leading wildcard ("*.example.com") matches one or more labels.
+// Requests for a matching host are only routed to its routes. The router middlewares also
+// apply to them. If pattern is invalid, the routes are never served and Validate reports the error.
+func (r *RadixRouter) Host(pattern string) *amaro.Group {
+	router, err := r.hosts.add(r.config, pattern, func() amaro.Router {
+		child := NewRadixRouter(WithRadixConfig(r.config))
+		child.host = pattern
+		child.parent = r
+		return child
+	})
+	r.registrations.fail(err)
+	return router.Group("")
+}
+
+// middlewares returns the router middlewares, preceded by those of the parent router.
+func (r *RadixRouter) middlewares() []amaro.Middleware {
+	if r.parent == nil {
+		return r.globalMiddlewares
+	}
+	parent := r.parent.middlewares()
+	return append(parent[:len(parent):len(parent)], r.globalMiddlewares...)
+}
+
+func (r *RadixRouter) GET(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodGet, path, handler, middlewares...)
+}
+func (r *RadixRouter) POST(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPost, path, handler, middlewares...)
+}
+func (r *RadixRouter) PUT(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPut, path, handler, middlewares...)
+}
+func (r *RadixRouter) DELETE(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodDelete, path, handler, middlewares...)
+}
+func (r *RadixRouter) PATCH(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPatch, path, handler, middlewares...)
+}
+func (r *RadixRouter) OPTIONS(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodOptions, path, handler, middlewares...)
+}
+func (r *RadixRouter) HEAD(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodHead, path, handler, middlewares...)
+}
+func (r *RadixRouter) Group(prefix string) *amaro.Group {
+	return amaro.NewGroup(prefix, r)
+}
@@ -0,0 +1,79 @@
+package routers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+)
+
+func TestRadixRouter_PrefixSplitting(t *testing.T) {
+	r := NewRadixRouter()
+	handler := func(c *amaro.Context) error { return nil }
+
+	for _, path := range []string{"/search", "/support", "/src/*filepath", "/s", "/search/:q"} {
+		if err := r.GET(path, handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// All routes share the "/s" prefix
+	root := r.root[http.MethodGet]
+	if len(root.children) != 1 || root.children[0].prefix != "/s" {
+		t.Fatalf("Expected a single /s child, got %+v", root.children)
+	}
+
+	ctx := amaro.NewContext(nil, nil)
+	for _, path := range []string{"/search", "/support", "/s", "/src/a.go", "/search/go"} {
+		ctx.Reset(nil, nil)
+		if _, err := r.Find(http.MethodGet, path, ctx); err != nil {
+			t.Errorf("%s: unexpected error: %v", path, err)
+		}
+	}
+	if ctx.PathParam("q") != "go" {
+		t.Errorf("Expected q=go, got %s", ctx.PathParam("q"))
+	}
+
+	for _, path := range []string{"/sea", "/supports", "/x"} {
+		if _, err := r.Find(http.MethodGet, path, nil); err == nil {
+			t.Errorf("%s: expected no match", path)
+		}
+	}
+}
+
+func TestRadixRouter_CustomConfig(t *testing.T) {
+	config := amaro.DefaultRouterConfig()
+	config.ParamParser = func(segment string) (bool, string) {
+		if len(segment) > 1 && segment[0] == '$' {
+			return true, segment[1:]
+		}
+		return false, ""
+	}
+	config.SegmentParser = nil
+
+	r := NewRadixRouter(WithRadixConfig(config))
+	r.GET("/users/$id", func(c *amaro.Context) error { return nil })
+
+	ctx := amaro.NewContext(nil, nil)
+	if _, err := r.Find(http.MethodGet, "/users/9", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.PathParam("id") != "9" {
+		t.Errorf("Expected id=9, got %s", ctx.PathParam("id"))
+	}
+}
+
+func TestRadixRouter_Syntax(t *testing.T) {
+	r := NewRadixRouter()
+	handler := func(c *amaro.Context) error { return nil }
+
+	r.GET("/files/:name.:ext", handler)
+
+	ctx := amaro.NewContext(nil, nil)
+	if _, err := r.Find(http.MethodGet, "/files/archive.tar.gz", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.PathParam("name") != "archive.tar" || ctx.PathParam("ext") != "gz" {
+		t.Errorf("Unexpected params %v", ctx.Params)
+	}
+}
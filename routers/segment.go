@@ -0,0 +1,357 @@
+package routers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// segmentKind classifies a path segment of a registered route.
+type segmentKind int
+
+const (
+	staticSegment segmentKind = iota
+	paramSegment
+	patternSegment
+	wildcardSegment
+)
+
+// segment is a parsed path segment of a registered route.
+type segment struct {
+	kind       segmentKind
+	text       string // segment as registered, without the optional marker
+	name       string // param or wildcard name
+	expr       string // param constraint expression
+	constraint amaro.Constraint
+	parts      []patternPart // multi-param segment parts
+	optional   bool
+}
+
+// patternPart is either a literal or a param of a multi-param segment.
+type patternPart struct {
+	literal    string
+	param      string
+	constraint amaro.Constraint
+}
+
+// normalizePath ensures path starts with a slash.
+func normalizePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	if path[0] != '/' {
+		return "/" + path
+	}
+	return path
+}
+
+// parseSegments splits path into segments using the parsers of config.
+// Empty segments are ignored.
+func parseSegments(config amaro.RouterConfig, path string) ([]segment, error) {
+	searchPath := strings.Trim(path, "/")
+	if searchPath == "" {
+		return nil, nil
+	}
+
+	var segments []segment
+	for _, part := range strings.Split(searchPath, "/") {
+		if part == "" {
+			continue
+		}
+
+		seg := segment{kind: staticSegment, text: part}
+		if config.OptionalParser != nil {
+			if stripped, ok := config.OptionalParser(part); ok && config.IsParam(stripped) {
+				seg.text, seg.optional = stripped, true
+			}
+		}
+		if !seg.optional && len(segments) > 0 && segments[len(segments)-1].optional {
+			return nil, fmt.Errorf("optional param must be trailing in %s", path)
+		}
+
+		// Use configured parsers
+		var segmentParts []amaro.SegmentPart
+		if config.SegmentParser != nil {
+			segmentParts = config.SegmentParser(seg.text)
+		}
+
+		isParam, paramName := false, ""
+		if segmentParts == nil && config.ParamParser != nil {
+			isParam, paramName = config.ParamParser(seg.text)
+		}
+
+		isWildcard, wildcardName := false, ""
+		if segmentParts == nil && !isParam && config.WildcardParser != nil {
+			isWildcard, wildcardName = config.WildcardParser(seg.text)
+		}
+
+		switch {
+		case segmentParts != nil:
+			if seg.optional {
+				return nil, fmt.Errorf("optional param must span a whole segment in %s", path)
+			}
+			parts, err := compileParts(config, seg.text, segmentParts)
+			if err != nil {
+				return nil, err
+			}
+			seg.kind, seg.parts = patternSegment, parts
+		case isParam:
+			name, expr, constraint, err := compileParam(config, paramName)
+			if err != nil {
+				return nil, err
+			}
+			seg.kind, seg.name, seg.expr, seg.constraint = paramSegment, name, expr, constraint
+		case isWildcard:
+			seg.kind, seg.name = wildcardSegment, wildcardName
+		}
+		if len(segments) > 0 && segments[len(segments)-1].kind == wildcardSegment {
+			return nil, conflict("segments after wildcard %s are shadowed in %s", segments[len(segments)-1].text, path)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// compileParam splits param into its name and constraint, and compiles the constraint.
+func compileParam(config amaro.RouterConfig, param string) (string, string, amaro.Constraint, error) {
+	name, expr := param, ""
+	if config.ConstraintParser != nil {
+		name, expr = config.ConstraintParser(param)
+	}
+	constraint, err := config.Constraint(expr)
+	return name, expr, constraint, err
+}
+
+// compileParts compiles the parts of a multi-param segment.
+func compileParts(config amaro.RouterConfig, text string, segmentParts []amaro.SegmentPart) ([]patternPart, error) {
+	parts := make([]patternPart, len(segmentParts))
+	for i, sp := range segmentParts {
+		if !sp.Param {
+			parts[i].literal = sp.Value
+			continue
+		}
+		if i > 0 && segmentParts[i-1].Param {
+			return nil, fmt.Errorf("params must be separated by a literal in segment %s", text)
+		}
+		name, _, constraint, err := compileParam(config, sp.Value)
+		if err != nil {
+			return nil, err
+		}
+		parts[i].param = name
+		parts[i].constraint = constraint
+	}
+	return parts, nil
+}
+
+// matchParts matches segment against parts, adding params to ctx on success.
+// Each param is greedy and backtracks to an earlier occurrence of the following literal.
+func matchParts(parts []patternPart, segment string, ctx *amaro.Context) bool {
+	if len(parts) == 0 {
+		return segment == ""
+	}
+	p := parts[0]
+	if p.param == "" {
+		if !strings.HasPrefix(segment, p.literal) {
+			return false
+		}
+		return matchParts(parts[1:], segment[len(p.literal):], ctx)
+	}
+
+	if len(parts) == 1 {
+		if segment == "" || (p.constraint != nil && !p.constraint(segment)) {
+			return false
+		}
+		if ctx != nil {
+			ctx.AddParam(p.param, segment)
+		}
+		return true
+	}
+
+	// The next part is always a literal
+	sep := parts[1].literal
+	for end := strings.LastIndex(segment, sep); end > 0; end = strings.LastIndex(segment[:end], sep) {
+		value := segment[:end]
+		if p.constraint != nil && !p.constraint(value) {
+			continue
+		}
+		var mark int
+		if ctx != nil {
+			mark = len(ctx.Params)
+			ctx.AddParam(p.param, value)
+		}
+		if matchParts(parts[1:], segment[end:], ctx) {
+			return true
+		}
+		if ctx != nil {
+			ctx.Params = ctx.Params[:mark]
+		}
+	}
+	return false
+}
+
+// paramEdge is a param edge of a tree node, optionally constrained.
+type paramEdge[N any] struct {
+	name       string
+	expr       string // constraint expression, empty if unconstrained
+	constraint amaro.Constraint
+	node       N
+}
+
+// addParamEdge returns the child for the param segment, creating it with newNode if needed.
+// Constrained siblings are kept before unconstrained ones so they are tried first.
+func addParamEdge[N any](edges *[]*paramEdge[N], seg segment, newNode func() N) (N, error) {
+	for _, p := range *edges {
+		if p.expr != seg.expr {
+			continue
+		}
+		if p.name != seg.name {
+			var zero N
+			return zero, conflict("param name %s vs %s", p.name, seg.name)
+		}
+		return p.node, nil
+	}
+
+	edge := &paramEdge[N]{
+		name:       seg.name,
+		expr:       seg.expr,
+		constraint: seg.constraint,
+		node:       newNode(),
+	}
+	list := *edges
+	i := len(list)
+	if edge.constraint != nil {
+		// Insert after the existing constrained params
+		i = 0
+		for i < len(list) && list[i].constraint != nil {
+			i++
+		}
+	}
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = edge
+	*edges = list
+	return edge.node, nil
+}
+
+// patternEdge is an edge for a segment holding several params, e.g. ":name.:ext".
+type patternEdge[N any] struct {
+	key   string // segment as registered
+	parts []patternPart
+	node  N
+}
+
+// addPatternEdge returns the child for the multi-param segment, creating it with newNode if needed.
+func addPatternEdge[N any](edges *[]*patternEdge[N], seg segment, newNode func() N) N {
+	for _, p := range *edges {
+		if p.key == seg.text {
+			return p.node
+		}
+	}
+	edge := &patternEdge[N]{key: seg.text, parts: seg.parts, node: newNode()}
+	*edges = append(*edges, edge)
+	return edge.node
+}
+
+// conflict returns an error wrapping amaro.ErrRouteConflict.
+func conflict(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", amaro.ErrRouteConflict, fmt.Sprintf(format, args...))
+}
+
+// registrations counts route registrations and records the errors of failed ones
+// so they can be reported together by Validate.
+type registrations struct {
+	count uint64
+	errs  []error
+}
+
+// record counts a registration of route, wrapping its error into an amaro.RouteError and recording it.
+func (rs *registrations) record(route amaro.Route, err error) error {
+	rs.count++
+	if err == nil {
+		return nil
+	}
+	err = &amaro.RouteError{Method: route.Method, Path: route.Path, Err: err}
+	rs.errs = append(rs.errs, err)
+	return err
+}
+
+// validate returns the recorded errors, joined with those of the host routers.
+func (rs *registrations) validate(h *hosts) error {
+	errs := rs.errs
+	for _, hr := range h.routers {
+		if v, ok := hr.router.(amaro.ValidatingRouter); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs[:len(errs):len(errs)], err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fail records an error that is not tied to a route, such as an invalid host pattern.
+func (rs *registrations) fail(err error) {
+	if err != nil {
+		rs.errs = append(rs.errs, err)
+	}
+}
+
+// version returns the number of registrations, including those of the host routers.
+func (rs *registrations) version(h *hosts) uint64 {
+	version := rs.count
+	for _, hr := range h.routers {
+		if vr, ok := hr.router.(amaro.VersionedRouter); ok {
+			version += vr.Version()
+		}
+	}
+	return version
+}
+
+// compileRoute prepends the router-level middlewares to the route and compiles them into its handler.
+func compileRoute(route amaro.Route, global []amaro.Middleware) amaro.Route {
+	middlewares := route.Middlewares
+	if len(global) > 0 {
+		combined := make([]amaro.Middleware, 0, len(global)+len(middlewares))
+		combined = append(combined, global...)
+		combined = append(combined, middlewares...)
+		middlewares = combined
+	}
+	if len(middlewares) > 0 {
+		route.Handler = amaro.Compile(route.Handler, middlewares...)
+	}
+	route.Middlewares = middlewares
+	route.Path = normalizePath(route.Path)
+	return route
+}
+
+// notFound builds the error returned by Find when no route matches.
+// matches reports whether the path matches the tree of another method.
+func notFound[N any](roots map[string]N, method string, matches func(N) bool) error {
+	var allowed []string
+	for m, n := range roots {
+		if m != method && matches(n) {
+			allowed = append(allowed, m)
+		}
+	}
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		return &amaro.MethodNotAllowedError{Allowed: allowed}
+	}
+	if _, ok := roots[method]; !ok {
+		return fmt.Errorf("method not found")
+	}
+	return amaro.NewHTTPError(http.StatusNotFound, "route not found")
+}
+
+// sortedMethods returns the keys of roots in order.
+func sortedMethods[N any](roots map[string]N) []string {
+	methods := make([]string, 0, len(roots))
+	for m := range roots {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
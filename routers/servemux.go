@@ -0,0 +1,521 @@
+package routers
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buildwithgo/amaro"
+)
+
+// muxParam maps a route param to the positional wildcard of its ServeMux pattern.
+type muxParam struct {
+	name       string
+	key        string // ServeMux wildcard name, empty for a wildcard matching nothing
+	constraint amaro.Constraint
+}
+
+// muxCandidate is a route sharing a ServeMux pattern with routes that only differ
+// in param names and constraints.
+type muxCandidate struct {
+	route  *amaro.Route
+	exprs  string // constraint expressions of the params
+	params []muxParam
+
+	// implicit is set when the route is served here because its trailing params are optional
+	implicit bool
+}
+
+// names reports whether c and other use the same param names.
+func (c *muxCandidate) names(other *muxCandidate) bool {
+	if len(c.params) != len(other.params) {
+		return false
+	}
+	for i := range c.params {
+		if c.params[i].name != other.params[i].name {
+			return false
+		}
+	}
+	return true
+}
+
+// constrained reports whether any param of c is constrained.
+func (c *muxCandidate) constrained() bool {
+	for _, p := range c.params {
+		if p.constraint != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// muxEntry is the handler registered on the ServeMux for a pattern.
+// Constrained candidates are kept first so they are tried first.
+type muxEntry struct {
+	candidates []*muxCandidate
+}
+
+// ServeHTTP records the first candidate whose constraints accept the path values.
+// It only runs during Find, with a muxMatch as writer.
+func (e *muxEntry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m, ok := w.(*muxMatch)
+	if !ok {
+		return
+	}
+	for _, c := range e.candidates {
+		matched := true
+		for _, p := range c.params {
+			if p.constraint != nil && !p.constraint(req.PathValue(p.key)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			m.candidate = c
+			return
+		}
+	}
+}
+
+// muxMatch captures the outcome of a ServeMux lookup.
+type muxMatch struct {
+	candidate *muxCandidate
+	header    http.Header
+}
+
+func (m *muxMatch) Header() http.Header {
+	if m.header == nil {
+		m.header = make(http.Header)
+	}
+	return m.header
+}
+
+func (m *muxMatch) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (m *muxMatch) WriteHeader(code int) {}
+
+// muxLookup holds the request and result of a lookup, pooled to limit allocations.
+type muxLookup struct {
+	req   http.Request
+	url   url.URL
+	match muxMatch
+}
+
+var lookupPool = sync.Pool{
+	New: func() interface{} { return new(muxLookup) },
+}
+
+func (l *muxLookup) release() {
+	l.req = http.Request{}
+	l.url = url.URL{}
+	l.match = muxMatch{}
+	lookupPool.Put(l)
+}
+
+// ServeMuxRouter is a router built on the method and wildcard patterns of http.ServeMux.
+// Routes use the amaro syntax of its RouterConfig and are translated to patterns such as
+// "GET /users/{id}", so matching follows the standard library: the most specific pattern wins.
+// Unlike http.ServeMux, GET routes do not answer HEAD, like in the other routers.
+// Constraints pick among routes differing only in param names and constraints, and params
+// are also set as path values of the request. Path policies apply like in the other routers,
+// except for case-insensitive lookups. Multi-param segments are not supported.
+type ServeMuxRouter struct {
+	mux               *http.ServeMux
+	entries           map[string]*muxEntry // ServeMux pattern -> entry
+	methods           map[string]bool
+	paramNames        map[string]string // method, pattern prefix and constraints -> param name
+	globalMiddlewares []amaro.Middleware
+	config            amaro.RouterConfig
+	hosts             hosts
+	host              string          // host pattern of a router created by Host
+	parent            *ServeMuxRouter // router that created it by Host
+	registrations     registrations
+}
+
+// ServeMuxRouterOption configures ServeMuxRouter.
+type ServeMuxRouterOption func(*ServeMuxRouter)
+
+// WithServeMuxConfig sets the router configuration.
+func WithServeMuxConfig(config amaro.RouterConfig) ServeMuxRouterOption {
+	return func(r *ServeMuxRouter) {
+		r.config = config
+	}
+}
+
+// NewServeMuxRouter creates a new instance of ServeMuxRouter.
+func NewServeMuxRouter(opts ...ServeMuxRouterOption) *ServeMuxRouter {
+	r := &ServeMuxRouter{
+		mux:        http.NewServeMux(),
+		entries:    make(map[string]*muxEntry),
+		methods:    make(map[string]bool),
+		paramNames: make(map[string]string),
+		config:     amaro.DefaultRouterConfig(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Use adds a global middleware to the router.
+// Note: These middlewares are applied to all routes registered AFTER calling Use.
+func (r *ServeMuxRouter) Use(middleware amaro.Middleware) {
+	r.globalMiddlewares = append(r.globalMiddlewares, middleware)
+}
+
+// Config returns the router configuration.
+func (r *ServeMuxRouter) Config() amaro.RouterConfig {
+	return r.config
+}
+
+func (r *ServeMuxRouter) Add(method, path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.AddRoute(amaro.Route{
+		Method:      method,
+		Path:        path,
+		Handler:     handler,
+		Middlewares: middlewares,
+	})
+}
+
+// AddRoute registers a fully described route.
+// Failed registrations are also recorded and reported by Validate.
+func (r *ServeMuxRouter) AddRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
+	}
+	return r.registrations.record(route, r.addRoute(route, false))
+}
+
+// ReplaceRoute registers route, replacing the route registered for its method and path.
+func (r *ServeMuxRouter) ReplaceRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
+	}
+	return r.registrations.record(route, r.addRoute(route, true))
+}
+
+// Version returns the number of registrations, including those of host routers.
+func (r *ServeMuxRouter) Version() uint64 {
+	return r.registrations.version(&r.hosts)
+}
+
+// Validate returns the errors of all failed registrations, including those of host routers.
+func (r *ServeMuxRouter) Validate() error {
+	return r.registrations.validate(&r.hosts)
+}
+
+// addRoute registers route. An existing route for the same path is an error unless replace is set.
+func (r *ServeMuxRouter) addRoute(route amaro.Route, replace bool) error {
+	segments, err := parseSegments(r.config, route.Path)
+	if err != nil {
+		return err
+	}
+
+	compiled := compileRoute(route, r.middlewares())
+
+	// Patterns preceding optional params and wildcards also serve the route
+	var implicit []*muxCandidate
+	var implicitPatterns []string
+
+	var pattern strings.Builder
+	var params []muxParam
+	var exprs []string
+	// Params at the same position must share their name, as in the tree routers
+	names := make(map[string]string)
+	for _, seg := range segments {
+		if seg.optional || seg.kind == wildcardSegment {
+			cand := &muxCandidate{
+				route:    &compiled,
+				exprs:    strings.Join(exprs, "\x00"),
+				params:   append([]muxParam(nil), params...),
+				implicit: true,
+			}
+			if seg.kind == wildcardSegment {
+				cand.params = append(cand.params, muxParam{name: seg.name})
+			}
+			implicit = append(implicit, cand)
+			implicitPatterns = append(implicitPatterns, pattern.String())
+		}
+
+		key := "p" + strconv.Itoa(len(params))
+		switch seg.kind {
+		case patternSegment:
+			return fmt.Errorf("multi-param segment %s is not supported by ServeMuxRouter", seg.text)
+		case paramSegment:
+			nameKey := route.Method + " " + pattern.String() + "\x00" + strings.Join(exprs, "\x00") + "\x00" + seg.expr
+			if name, ok := r.paramNames[nameKey]; ok && name != seg.name {
+				return conflict("param name %s vs %s", name, seg.name)
+			}
+			names[nameKey] = seg.name
+			pattern.WriteString("/{" + key + "}")
+			params = append(params, muxParam{name: seg.name, key: key, constraint: seg.constraint})
+			exprs = append(exprs, seg.expr)
+		case wildcardSegment:
+			pattern.WriteString("/{" + key + "...}")
+			params = append(params, muxParam{name: seg.name, key: key})
+			exprs = append(exprs, "")
+		default:
+			pattern.WriteString("/" + seg.text)
+		}
+	}
+
+	cand := &muxCandidate{
+		route:  &compiled,
+		exprs:  strings.Join(exprs, "\x00"),
+		params: params,
+	}
+	if err := r.register(route.Method, pattern.String(), cand, replace); err != nil {
+		return err
+	}
+	for i, cand := range implicit {
+		if err := r.register(route.Method, implicitPatterns[i], cand, false); err != nil {
+			return err
+		}
+	}
+	for key, name := range names {
+		r.paramNames[key] = name
+	}
+	return nil
+}
+
+// register adds cand to the entry of the ServeMux pattern for method and path,
+// registering the entry with the ServeMux when it is new. With replace, cand takes the
+// place of an explicit candidate with the same params.
+func (r *ServeMuxRouter) register(method, path string, cand *muxCandidate, replace bool) error {
+	if path == "" {
+		// "/" alone would match every path
+		path = "/{$}"
+	}
+	pattern := muxMethod(method) + " " + path
+
+	e, ok := r.entries[pattern]
+	if !ok {
+		e = &muxEntry{}
+		if err := r.handle(pattern, e); err != nil {
+			return err
+		}
+		r.entries[pattern] = e
+		r.methods[method] = true
+	}
+
+	for i, c := range e.candidates {
+		if c.exprs != cand.exprs {
+			continue
+		}
+		if !c.names(cand) {
+			return conflict("param names of %s vs %s", c.route.Path, cand.route.Path)
+		}
+		// Explicit registrations take precedence
+		if cand.implicit && !c.implicit {
+			return nil
+		}
+		if !cand.implicit && !c.implicit && !replace {
+			return conflict("already registered as %s", c.route.Path)
+		}
+		e.candidates[i] = cand
+		return nil
+	}
+
+	i := len(e.candidates)
+	if cand.constrained() {
+		// Insert after the existing constrained candidates
+		i = 0
+		for i < len(e.candidates) && e.candidates[i].constrained() {
+			i++
+		}
+	}
+	e.candidates = append(e.candidates, nil)
+	copy(e.candidates[i+1:], e.candidates[i:])
+	e.candidates[i] = cand
+	return nil
+}
+
+// headMethod stands for HEAD in ServeMux patterns, since a GET pattern also matches HEAD.
+const headMethod = "HEAD_"
+
+// muxMethod returns the method used in ServeMux patterns and lookups for method.
+func muxMethod(method string) string {
+	if method == http.MethodHead {
+		return headMethod
+	}
+	return method
+}
+
+// handle registers h on the ServeMux, turning its panics on invalid or conflicting patterns into errors.
+func (r *ServeMuxRouter) handle(pattern string, h http.Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%v", v)
+			if strings.Contains(err.Error(), "conflicts with") {
+				err = conflict("%v", v)
+			}
+		}
+	}()
+	r.mux.Handle(pattern, h)
+	return nil
+}
+
+func (r *ServeMuxRouter) Find(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	if route, ok, err := r.hosts.find(method, path, ctx); ok {
+		return route, err
+	}
+	// Case-insensitive lookups are not supported
+	return findCanonical(r.config, method, path, ctx, r.lookup, nil)
+}
+
+// lookup finds the route of path, ignoring a trailing slash.
+// Paths are clean when called through Find, so the ServeMux never redirects.
+func (r *ServeMuxRouter) lookup(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+
+	var base *http.Request
+	if ctx != nil {
+		base = ctx.Request
+	}
+	if route := r.match(base, method, path, ctx); route != nil {
+		return route, nil
+	}
+
+	// The path may still be registered under other methods
+	var allowed []string
+	for m := range r.methods {
+		if m != method && r.match(base, m, path, nil) != nil {
+			allowed = append(allowed, m)
+		}
+	}
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		return nil, &amaro.MethodNotAllowedError{Allowed: allowed}
+	}
+	if !r.methods[method] {
+		return nil, fmt.Errorf("method not found")
+	}
+	return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+}
+
+// match looks up method and path on the ServeMux, adding the path values to ctx on success.
+func (r *ServeMuxRouter) match(base *http.Request, method, path string, ctx *amaro.Context) *amaro.Route {
+	l := lookupPool.Get().(*muxLookup)
+	defer l.release()
+
+	req, m := &l.req, &l.match
+	if base != nil {
+		*req = *base
+	}
+	l.url.Path = path
+	req.Method = muxMethod(method)
+	req.URL = &l.url
+	req.RequestURI = path
+
+	r.mux.ServeHTTP(m, req)
+
+	if m.candidate != nil {
+		if ctx != nil {
+			for _, p := range m.candidate.params {
+				value := ""
+				if p.key != "" {
+					value = req.PathValue(p.key)
+				}
+				ctx.AddParam(p.name, value)
+				if ctx.Request != nil {
+					ctx.Request.SetPathValue(p.name, value)
+				}
+			}
+		}
+		return m.candidate.route
+	}
+	return nil
+}
+
+func (r *ServeMuxRouter) Routes() []amaro.Route {
+	// Sort patterns for deterministic output
+	patterns := make([]string, 0, len(r.entries))
+	for p := range r.entries {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	var routes []amaro.Route
+	for _, p := range patterns {
+		for _, c := range r.entries[p].candidates {
+			if !c.implicit {
+				routes = append(routes, *c.route)
+			}
+		}
+	}
+	return append(routes, r.hosts.routes()...)
+}
+
+func (r *ServeMuxRouter) StaticFS(pathPrefix string, fsys fs.FS) {
+	handler := amaro.StaticHandler(amaro.StaticConfig{
+		Root:   fsys,
+		Prefix: pathPrefix,
+	})
+
+	path := strings.TrimRight(pathPrefix, "/")
+	r.Add(http.MethodGet, path, handler)
+	r.Add(http.MethodHead, path, handler)
+
+	wildcardPath := path + "/*filepath"
+	r.Add(http.MethodGet, wildcardPath, handler)
+	r.Add(http.MethodHead, wildcardPath, handler)
+}
+
+// Host returns a group whose routes only match requests for hosts matching pattern,
+// e.g. "api.example.com" or "{tenant}.example.com". Params match a single label and are
+// read through Context.PathParam; a leading wildcard ("*.example.com") matches one or more labels.
+// Requests for a matching host are only routed to its routes. The router middlewares also
+// apply to them. If pattern is invalid, the routes are never served and Validate reports the error.
+func (r *ServeMuxRouter) Host(pattern string) *amaro.Group {
+	router, err := r.hosts.add(r.config, pattern, func() amaro.Router {
+		child := NewServeMuxRouter(WithServeMuxConfig(r.config))
+		child.host = pattern
+		child.parent = r
+		return child
+	})
+	r.registrations.fail(err)
+	return router.Group("")
+}
+
+// middlewares returns the router middlewares, preceded by those of the parent router.
+func (r *ServeMuxRouter) middlewares() []amaro.Middleware {
+	if r.parent == nil {
+		return r.globalMiddlewares
+	}
+	parent := r.parent.middlewares()
+	return append(parent[:len(parent):len(parent)], r.globalMiddlewares...)
+}
+
+func (r *ServeMuxRouter) GET(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodGet, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) POST(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPost, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) PUT(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPut, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) DELETE(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodDelete, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) PATCH(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodPatch, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) OPTIONS(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodOptions, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) HEAD(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
+	return r.Add(http.MethodHead, path, handler, middlewares...)
+}
+func (r *ServeMuxRouter) Group(prefix string) *amaro.Group {
+	return amaro.NewGroup(prefix, r)
+}
@@ -0,0 +1,76 @@
+package routers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestServeMuxRouter_App(t *testing.T) {
+	config := amaro.DefaultRouterConfig()
+	config.CleanPath = amaro.PathRedirect
+	app := amaro.New(amaro.WithRouter(routers.NewServeMuxRouter(routers.WithServeMuxConfig(config))))
+
+	api := app.Group("/api")
+	api.GET("/users/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "user "+c.Request.PathValue("id"))
+	})
+	api.DELETE("/users/{id}", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "deleted "+c.PathParam("id"))
+	})
+	api.HEAD("/status", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "")
+	})
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/api/users/42", http.StatusOK, "user 42"},
+		{http.MethodDelete, "/api/users/42", http.StatusOK, "deleted 42"},
+		// Unlike http.ServeMux, GET routes do not answer HEAD
+		{http.MethodHead, "/api/users/42", http.StatusMethodNotAllowed, ""},
+		{http.MethodHead, "/api/status", http.StatusOK, ""},
+		{http.MethodPost, "/api/users/42", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/api/users", http.StatusNotFound, ""},
+	}
+	for _, tc := range cases {
+		w := app.Test(httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.code {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.code, w.Code)
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Errorf("%s %s: expected body %q, got %q", tc.method, tc.path, tc.body, w.Body.String())
+		}
+	}
+
+	// Unclean paths are redirected
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.URL.Path = "/api/../api/users/7"
+	w := app.Test(req)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/api/users/7" {
+		t.Errorf("Expected redirect to /api/users/7, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestServeMuxRouter_Unsupported(t *testing.T) {
+	r := routers.NewServeMuxRouter()
+	handler := func(c *amaro.Context) error { return nil }
+
+	if err := r.GET("/files/:name.:ext", handler); err == nil {
+		t.Error("Expected error for multi-param segment")
+	}
+
+	// Neither pattern is more specific than the other
+	if err := r.GET("/a/:x/b", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.GET("/a/b/:y", handler); err == nil {
+		t.Error("Expected error for conflicting patterns")
+	}
+}
@@ -1,7 +1,6 @@
 package routers
 
 import (
-	"fmt"
 	"io/fs"
 	"net/http"
 	"sort"
@@ -14,14 +13,21 @@ type node struct {
 	// Static children
 	children map[string]*node
 
-	// Dynamic children
-	paramNode    *node
-	paramName    string
+	// Dynamic children: multi-param segments, then params with constrained ones first
+	patterns []*patternEdge[*node]
+	params   []*paramEdge[*node]
 
 	catchAllNode *node
 	catchAllName string
 
 	amaro.Route
+
+	// implicit is set when the route is served here because its trailing params are optional
+	implicit bool
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
 }
 
 // TrieRouter is a trie-based router using a map for children.
@@ -30,6 +36,10 @@ type TrieRouter struct {
 	root              map[string]*node // method -> root node
 	globalMiddlewares []amaro.Middleware
 	config            amaro.RouterConfig
+	hosts             hosts
+	host              string      // host pattern of a router created by Host
+	parent            *TrieRouter // router that created it by Host
+	registrations     registrations
 }
 
 // TrieRouterOption configures TrieRouter.
@@ -61,195 +71,336 @@ func (r *TrieRouter) Use(middleware amaro.Middleware) {
 	r.globalMiddlewares = append(r.globalMiddlewares, middleware)
 }
 
+// Config returns the router configuration.
+func (r *TrieRouter) Config() amaro.RouterConfig {
+	return r.config
+}
+
 func (r *TrieRouter) Add(method, path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
-	// Prepend router-level middlewares to the route-specific middlewares
-	if len(r.globalMiddlewares) > 0 {
-		combined := make([]amaro.Middleware, 0, len(r.globalMiddlewares)+len(middlewares))
-		combined = append(combined, r.globalMiddlewares...)
-		combined = append(combined, middlewares...)
-		middlewares = combined
+	return r.AddRoute(amaro.Route{
+		Method:      method,
+		Path:        path,
+		Handler:     handler,
+		Middlewares: middlewares,
+	})
+}
+
+// AddRoute registers a fully described route.
+// Failed registrations are also recorded and reported by Validate.
+func (r *TrieRouter) AddRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
 	}
-	if _, ok := r.root[method]; !ok {
-		r.root[method] = &node{children: make(map[string]*node)}
+	return r.registrations.record(route, r.addRoute(route, false))
+}
+
+// ReplaceRoute registers route, replacing the route registered for its method and path.
+func (r *TrieRouter) ReplaceRoute(route amaro.Route) error {
+	if route.Host == "" {
+		route.Host = r.host
 	}
-	n := r.root[method]
+	return r.registrations.record(route, r.addRoute(route, true))
+}
+
+// Version returns the number of registrations, including those of host routers.
+func (r *TrieRouter) Version() uint64 {
+	return r.registrations.version(&r.hosts)
+}
 
-	// Normalize path
-	if path == "" {
-		path = "/"
+// Validate returns the errors of all failed registrations, including those of host routers.
+func (r *TrieRouter) Validate() error {
+	return r.registrations.validate(&r.hosts)
+}
+
+// addRoute registers route. An existing route for the same path is an error unless replace is set.
+func (r *TrieRouter) addRoute(route amaro.Route, replace bool) error {
+	segments, err := parseSegments(r.config, route.Path)
+	if err != nil {
+		return err
 	}
-	if path[0] != '/' {
-		path = "/" + path
+
+	if _, ok := r.root[route.Method]; !ok {
+		r.root[route.Method] = newNode()
 	}
+	n := r.root[route.Method]
 
-	searchPath := strings.Trim(path, "/")
+	// Nodes preceding optional params also serve the route
+	var implicit []*node
 
-	if searchPath != "" {
-		parts := strings.Split(searchPath, "/")
-		for _, part := range parts {
-			if part == "" {
-				continue
-			}
+	for _, seg := range segments {
+		if seg.optional {
+			implicit = append(implicit, n)
+		}
 
-			// Use configured parsers
-			isParam, paramName := false, ""
-			if r.config.ParamParser != nil {
-				isParam, paramName = r.config.ParamParser(part)
+		switch seg.kind {
+		case patternSegment:
+			n = addPatternEdge(&n.patterns, seg, newNode)
+		case paramSegment:
+			child, err := addParamEdge(&n.params, seg, newNode)
+			if err != nil {
+				return err
 			}
-
-			isWildcard, wildcardName := false, ""
-			if !isParam && r.config.WildcardParser != nil {
-				isWildcard, wildcardName = r.config.WildcardParser(part)
+			n = child
+		case wildcardSegment:
+			if n.catchAllNode == nil {
+				n.catchAllNode = newNode()
+				n.catchAllName = seg.name
 			}
-
-			if isParam {
-				if n.paramNode == nil {
-					n.paramNode = &node{children: make(map[string]*node)}
-					n.paramName = paramName
-				}
-				if n.paramName != paramName {
-					return fmt.Errorf("param name conflict: %s vs %s", n.paramName, paramName)
-				}
-				n = n.paramNode
-			} else if isWildcard {
-				if n.catchAllNode == nil {
-					n.catchAllNode = &node{children: make(map[string]*node)}
-					n.catchAllName = wildcardName
-				}
-				if n.catchAllName != wildcardName {
-					return fmt.Errorf("wildcard name conflict: %s vs %s", n.catchAllName, wildcardName)
-				}
-				n = n.catchAllNode
-			} else {
-				// Static
-				if n.children == nil {
-					n.children = make(map[string]*node)
-				}
-				if _, ok := n.children[part]; !ok {
-					n.children[part] = &node{children: make(map[string]*node)}
-				}
-				n = n.children[part]
+			if n.catchAllName != seg.name {
+				return conflict("wildcard name %s vs %s", n.catchAllName, seg.name)
+			}
+			n = n.catchAllNode
+		default:
+			if _, ok := n.children[seg.text]; !ok {
+				n.children[seg.text] = newNode()
 			}
+			n = n.children[seg.text]
 		}
 	}
 
-	// Compile middlewares into handler
-	finalHandler := handler
-	if len(middlewares) > 0 {
-		finalHandler = amaro.Compile(handler, middlewares...)
+	if n.Handler != nil && !n.implicit && !replace {
+		return conflict("already registered as %s", n.Path)
 	}
+	n.Route = compileRoute(route, r.middlewares())
+	n.implicit = false
 
-	n.Handler = finalHandler
-	n.Middlewares = middlewares
-	n.Path = path
-	n.Method = method
+	for _, in := range implicit {
+		// Explicit registrations take precedence
+		if in.Handler != nil && !in.implicit {
+			continue
+		}
+		in.Route = n.Route
+		in.implicit = true
+	}
 
 	return nil
 }
 
 func (r *TrieRouter) Find(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	if route, ok, err := r.hosts.find(method, path, ctx); ok {
+		return route, err
+	}
+	return findCanonical(r.config, method, path, ctx, r.lookup, r.lookupFold)
+}
+
+// lookup finds the route of path, ignoring empty segments and a trailing slash.
+func (r *TrieRouter) lookup(method, path string, ctx *amaro.Context) (*amaro.Route, error) {
+	searchPath := trimSlashes(path)
+
+	if n, ok := r.root[method]; ok {
+		if route := walk(n, searchPath, ctx); route != nil {
+			return route, nil
+		}
+	}
+
+	// The path may still be registered under other methods
+	return nil, notFound(r.root, method, func(n *node) bool {
+		return walk(n, searchPath, nil) != nil
+	})
+}
+
+// lookupFold is lookup with static segments compared case-insensitively.
+func (r *TrieRouter) lookupFold(method, path string, ctx *amaro.Context) (*amaro.Route, string) {
 	n, ok := r.root[method]
 	if !ok {
-		return nil, fmt.Errorf("method not found")
+		return nil, ""
 	}
+	route, fixed := walkFold(n, trimSlashes(path), ctx, make([]byte, 0, len(path)+1))
+	if route == nil {
+		return nil, ""
+	}
+	if len(fixed) == 0 {
+		return route, "/"
+	}
+	return route, string(fixed)
+}
 
-	searchPath := path
-	if len(searchPath) > 0 && searchPath[0] == '/' {
-		searchPath = searchPath[1:]
+// trimSlashes removes one leading and one trailing slash from path.
+func trimSlashes(path string) string {
+	if len(path) > 0 && path[0] == '/' {
+		path = path[1:]
 	}
-	if len(searchPath) > 0 && searchPath[len(searchPath)-1] == '/' {
-		searchPath = searchPath[:len(searchPath)-1]
+	if len(path) > 0 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
 	}
+	return path
+}
 
-	// Zero-allocation iteration
-	for len(searchPath) > 0 || n != nil {
-		// Capture the current path state before slicing, to use in CatchAll
-		currentPath := searchPath
+// walk matches searchPath, with its leading and trailing slash trimmed, against the
+// subtree rooted at n. Priority is Static > Multi-param > Param > Wildcard; when a deeper lookup
+// fails it backtracks to the next candidate, discarding params added by the failed branch.
+// It returns nil if no route matches, leaving ctx.Params untouched.
+func walk(n *node, searchPath string, ctx *amaro.Context) *amaro.Route {
+	// Skip empty segments
+	for len(searchPath) > 0 && searchPath[0] == '/' {
+		searchPath = searchPath[1:]
+	}
 
-		if len(searchPath) == 0 {
-			if n.Handler != nil {
-				return &n.Route, nil
-			}
-			if n.catchAllNode != nil {
-				if ctx != nil {
-					ctx.AddParam(n.catchAllName, "")
-				}
-				if n.catchAllNode.Handler != nil {
-					return &n.catchAllNode.Route, nil
-				}
+	if len(searchPath) == 0 {
+		if n.Handler != nil {
+			return &n.Route
+		}
+		if n.catchAllNode != nil && n.catchAllNode.Handler != nil {
+			if ctx != nil {
+				ctx.AddParam(n.catchAllName, "")
 			}
-			return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+			return &n.catchAllNode.Route
 		}
+		return nil
+	}
 
-		var part string
-		i := strings.IndexByte(searchPath, '/')
-		if i < 0 {
-			part = searchPath
-			searchPath = ""
-		} else {
-			part = searchPath[:i]
-			searchPath = searchPath[i+1:]
-		}
+	part, rest := searchPath, ""
+	if i := strings.IndexByte(searchPath, '/'); i >= 0 {
+		part, rest = searchPath[:i], searchPath[i+1:]
+	}
 
-		if part == "" {
-			continue
+	// 1. Static
+	if child, found := n.children[part]; found {
+		if route := walk(child, rest, ctx); route != nil {
+			return route
 		}
+	}
 
-		// Priority: Static > Param > Wildcard
+	// 2. Multi-param segments
+	for _, p := range n.patterns {
+		var mark int
+		if ctx != nil {
+			mark = len(ctx.Params)
+		}
+		if matchParts(p.parts, part, ctx) {
+			if route := walk(p.node, rest, ctx); route != nil {
+				return route
+			}
+		}
+		if ctx != nil {
+			ctx.Params = ctx.Params[:mark]
+		}
+	}
 
-		// 1. Static
-		if child, found := n.children[part]; found {
-			n = child
+	// 3. Param, constrained first
+	for _, p := range n.params {
+		if p.constraint != nil && !p.constraint(part) {
 			continue
 		}
+		var mark int
+		if ctx != nil {
+			mark = len(ctx.Params)
+			ctx.AddParam(p.name, part)
+		}
+		if route := walk(p.node, rest, ctx); route != nil {
+			return route
+		}
+		if ctx != nil {
+			ctx.Params = ctx.Params[:mark]
+		}
+	}
+
+	// 4. CatchAll
+	if n.catchAllNode != nil && n.catchAllNode.Handler != nil {
+		if ctx != nil {
+			ctx.AddParam(n.catchAllName, searchPath)
+		}
+		return &n.catchAllNode.Route
+	}
 
-		// 2. Param
-		if n.paramNode != nil {
+	return nil
+}
+
+// walkFold is walk with static segments compared case-insensitively.
+// It also returns fixed extended with the matched path in the registered casing.
+func walkFold(n *node, searchPath string, ctx *amaro.Context, fixed []byte) (*amaro.Route, []byte) {
+	// Skip empty segments
+	for len(searchPath) > 0 && searchPath[0] == '/' {
+		searchPath = searchPath[1:]
+	}
+
+	if len(searchPath) == 0 {
+		if n.Handler != nil {
+			return &n.Route, fixed
+		}
+		if n.catchAllNode != nil && n.catchAllNode.Handler != nil {
 			if ctx != nil {
-				ctx.AddParam(n.paramName, part)
+				ctx.AddParam(n.catchAllName, "")
 			}
-			n = n.paramNode
+			return &n.catchAllNode.Route, fixed
+		}
+		return nil, fixed
+	}
+
+	part, rest := searchPath, ""
+	if i := strings.IndexByte(searchPath, '/'); i >= 0 {
+		part, rest = searchPath[:i], searchPath[i+1:]
+	}
+	mark := len(fixed)
+	var paramMark int
+	if ctx != nil {
+		paramMark = len(ctx.Params)
+	}
+
+	// 1. Static, in any casing
+	for key, child := range n.children {
+		if !strings.EqualFold(key, part) {
 			continue
 		}
+		if route, f := walkFold(child, rest, ctx, append(append(fixed[:mark], '/'), key...)); route != nil {
+			return route, f
+		}
+	}
 
-		// 3. CatchAll
-		if n.catchAllNode != nil {
-			if ctx != nil {
-				ctx.AddParam(n.catchAllName, currentPath)
+	// 2. Multi-param segments
+	for _, p := range n.patterns {
+		if matchParts(p.parts, part, ctx) {
+			if route, f := walkFold(p.node, rest, ctx, append(append(fixed[:mark], '/'), part...)); route != nil {
+				return route, f
 			}
-			if n.catchAllNode.Handler != nil {
-				return &n.catchAllNode.Route, nil
-			}
-			return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
 		}
+		if ctx != nil {
+			ctx.Params = ctx.Params[:paramMark]
+		}
+	}
 
-		return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+	// 3. Param, constrained first
+	for _, p := range n.params {
+		if p.constraint != nil && !p.constraint(part) {
+			continue
+		}
+		if ctx != nil {
+			ctx.AddParam(p.name, part)
+		}
+		if route, f := walkFold(p.node, rest, ctx, append(append(fixed[:mark], '/'), part...)); route != nil {
+			return route, f
+		}
+		if ctx != nil {
+			ctx.Params = ctx.Params[:paramMark]
+		}
 	}
 
-	return nil, amaro.NewHTTPError(http.StatusNotFound, "route not found")
+	// 4. CatchAll
+	if n.catchAllNode != nil && n.catchAllNode.Handler != nil {
+		if ctx != nil {
+			ctx.AddParam(n.catchAllName, searchPath)
+		}
+		return &n.catchAllNode.Route, append(append(fixed[:mark], '/'), searchPath...)
+	}
+
+	return nil, fixed[:mark]
 }
 
 func (r *TrieRouter) Routes() []amaro.Route {
 	var routes []amaro.Route
 
 	// Sort methods for deterministic output
-	var methods []string
-	for m := range r.root {
-		methods = append(methods, m)
-	}
-	sort.Strings(methods)
-
-	for _, method := range methods {
+	for _, method := range sortedMethods(r.root) {
 		walkNode(r.root[method], &routes)
 	}
-	return routes
+	return append(routes, r.hosts.routes()...)
 }
 
 func walkNode(n *node, routes *[]amaro.Route) {
 	if n == nil {
 		return
 	}
-	if n.Handler != nil {
+	if n.Handler != nil && !n.implicit {
 		*routes = append(*routes, n.Route)
 	}
 
@@ -263,8 +414,13 @@ func walkNode(n *node, routes *[]amaro.Route) {
 		walkNode(n.children[k], routes)
 	}
 
-	// Walk param
-	walkNode(n.paramNode, routes)
+	// Walk multi-param segments and params
+	for _, p := range n.patterns {
+		walkNode(p.node, routes)
+	}
+	for _, p := range n.params {
+		walkNode(p.node, routes)
+	}
 
 	// Walk wildcard
 	walkNode(n.catchAllNode, routes)
@@ -285,6 +441,31 @@ func (r *TrieRouter) StaticFS(pathPrefix string, fsys fs.FS) {
 	r.Add(http.MethodHead, wildcardPath, handler)
 }
 
+// Host returns a group whose routes only match requests for hosts matching pattern,
+// e.g. "api.example.com" or "{tenant}.example.com". Params match a single label and are
+// read through Context.PathParam; a leading wildcard ("*.example.com") matches one or more labels.
+// Requests for a matching host are only routed to its routes. The router middlewares also
+// apply to them. If pattern is invalid, the routes are never served and Validate reports the error.
+func (r *TrieRouter) Host(pattern string) *amaro.Group {
+	router, err := r.hosts.add(r.config, pattern, func() amaro.Router {
+		child := NewTrieRouter(WithConfig(r.config))
+		child.host = pattern
+		child.parent = r
+		return child
+	})
+	r.registrations.fail(err)
+	return router.Group("")
+}
+
+// middlewares returns the router middlewares, preceded by those of the parent router.
+func (r *TrieRouter) middlewares() []amaro.Middleware {
+	if r.parent == nil {
+		return r.globalMiddlewares
+	}
+	parent := r.parent.middlewares()
+	return append(parent[:len(parent):len(parent)], r.globalMiddlewares...)
+}
+
 func (r *TrieRouter) GET(path string, handler amaro.Handler, middlewares ...amaro.Middleware) error {
 	return r.Add(http.MethodGet, path, handler, middlewares...)
 }
@@ -1,8 +1,10 @@
 package routers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/buildwithgo/amaro"
@@ -151,3 +153,32 @@ func TestTrieRouter_DynamicConflict(t *testing.T) {
 		t.Errorf("Expected id=123, got %s", val)
 	}
 }
+
+func TestTrieRouter_MethodNotAllowed(t *testing.T) {
+	r := NewTrieRouter()
+	handler := func(c *amaro.Context) error { return nil }
+
+	r.GET("/users/:id", handler)
+	r.PUT("/users/:id", handler)
+	r.POST("/users", handler)
+
+	ctx := amaro.NewContext(nil, nil)
+	_, err := r.Find(http.MethodDelete, "/users/123", ctx)
+
+	var mna *amaro.MethodNotAllowedError
+	if !errors.As(err, &mna) {
+		t.Fatalf("Expected MethodNotAllowedError, got %v", err)
+	}
+	if got := strings.Join(mna.Allowed, ","); got != "GET,PUT" {
+		t.Errorf("Expected allowed GET,PUT, got %s", got)
+	}
+	if len(ctx.Params) != 0 {
+		t.Errorf("Expected no params after failed match, got %v", ctx.Params)
+	}
+
+	// Registered method but unknown path is still a 404
+	_, err = r.Find(http.MethodPost, "/missing", ctx)
+	if errors.As(err, &mna) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
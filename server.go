@@ -0,0 +1,556 @@
+package amaro
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ServerConfig configures the http.Server instances started by Run, RunTLS, RunUnix, Listener and Serve.
+// Zero durations disable the corresponding timeout, so start from DefaultServerConfig.
+type ServerConfig struct {
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout, IdleTimeout and MaxHeaderBytes
+	// are passed to http.Server.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// TLSConfig is used by RunTLS and by TLS listeners without their own TLSConfig.
+	// Certificates loaded from certificate files are added to it.
+	TLSConfig *tls.Config
+
+	// CertReloadInterval enables certificate rotation without a restart: if positive, the
+	// certificate files of RunTLS and TLS listeners are checked for changes at most once per
+	// interval and reloaded, see CertReloader.
+	CertReloadInterval time.Duration
+
+	// ClientCAFile enables mutual TLS on TLS listeners: client certificates are verified
+	// against the PEM encoded CA bundle in the file.
+	ClientCAFile string
+
+	// ClientAuth is the client certificate policy used with ClientCAFile.
+	// Defaults to tls.RequireAndVerifyClientCert.
+	ClientAuth tls.ClientAuthType
+
+	// ShutdownTimeout bounds the graceful shutdown or upgrade started by a signal.
+	ShutdownTimeout time.Duration
+
+	// Signals start a graceful shutdown when received. No signals are handled if empty.
+	Signals []os.Signal
+
+	// UpgradeSignals start a graceful binary upgrade with Upgrade when received,
+	// typically syscall.SIGHUP or syscall.SIGUSR2. No signals are handled if empty.
+	UpgradeSignals []os.Signal
+
+	// HTTP2 configures HTTP/2, such as MaxConcurrentStreams and MaxReadFrameSize.
+	// Zero fields keep the defaults of net/http.
+	HTTP2 *http.HTTP2Config
+
+	// ConfigureServer is called with every server before it starts serving, and whether the
+	// server uses TLS. It can set fields not covered here or wrap the handler, e.g. with the
+	// addons/h2c package to serve HTTP/2 over cleartext.
+	ConfigureServer func(srv *http.Server, tls bool) error
+
+	// ErrorLog is passed to http.Server. The log package's standard logger is used if nil.
+	ErrorLog *log.Logger
+}
+
+// DefaultServerConfig returns the server configuration used by New.
+// Read and write timeouts are left unset so that streaming and websocket handlers keep working.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   5 * time.Second,
+		Signals:           []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// WithServerConfig sets the configuration of the servers started by the App.
+func WithServerConfig(config ServerConfig) AppOption {
+	return func(a *App) {
+		a.server = config
+	}
+}
+
+// lifecycle tracks the running servers and the start and shutdown hooks of an App.
+type lifecycle struct {
+	mu         sync.Mutex
+	bindings   []binding
+	closing    bool
+	done       chan struct{}
+	once       sync.Once
+	err        error
+	onStart    []func() error
+	onShutdown []func(ctx context.Context) error
+}
+
+// register adds bindings to the running set. It reports false if the App is shutting down.
+func (l *lifecycle) register(bindings ...binding) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return false
+	}
+	l.bindings = append(l.bindings, bindings...)
+	return true
+}
+
+// shuttingDown reports whether Shutdown has been called.
+func (l *lifecycle) shuttingDown() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closing
+}
+
+// doneChan returns a channel that is closed once Shutdown has completed.
+func (l *lifecycle) doneChan() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.done == nil {
+		l.done = make(chan struct{})
+	}
+	return l.done
+}
+
+// OnStart registers a hook that runs before the App starts serving.
+// Hooks run in the order they were added; an error aborts the start.
+func (a *App) OnStart(hook func() error) {
+	a.lifecycle.mu.Lock()
+	defer a.lifecycle.mu.Unlock()
+	a.lifecycle.onStart = append(a.lifecycle.onStart, hook)
+}
+
+// OnShutdown registers a hook that runs during Shutdown after the servers have drained
+// their connections, e.g. to close database pools. Hooks run in the order they were added
+// and receive the shutdown context.
+func (a *App) OnShutdown(hook func(ctx context.Context) error) {
+	a.lifecycle.mu.Lock()
+	defer a.lifecycle.mu.Unlock()
+	a.lifecycle.onShutdown = append(a.lifecycle.onShutdown, hook)
+}
+
+// Shutdown gracefully stops the servers started by the App, then runs the OnShutdown hooks.
+// Servers that do not drain before ctx is done are closed. Run and its variants return nil
+// once Shutdown has completed. Subsequent calls wait for the first one and return its result.
+func (a *App) Shutdown(ctx context.Context) error {
+	l := &a.lifecycle
+	l.once.Do(func() {
+		l.mu.Lock()
+		l.closing = true
+		bindings := l.bindings
+		l.bindings = nil
+		hooks := l.onShutdown
+		l.mu.Unlock()
+
+		var mu sync.Mutex
+		var errs []error
+		var wg sync.WaitGroup
+		for _, b := range bindings {
+			wg.Go(func() {
+				if err := b.server.Shutdown(ctx); err != nil {
+					// Force close if graceful shutdown fails
+					b.server.Close()
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+
+		for _, hook := range hooks {
+			if err := hook(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		l.err = errors.Join(errs...)
+		close(l.doneChan())
+	})
+	return l.err
+}
+
+// Listener serves the app on l until Shutdown is called or a configured signal is received.
+func (a *App) Listener(l net.Listener) error {
+	if err := a.validate(); err != nil {
+		l.Close()
+		return err
+	}
+	return a.serve(binding{listener: l})
+}
+
+// RunUnix serves the app on a Unix domain socket at path.
+// A stale socket file left behind by a previous run is removed first.
+func (a *App) RunUnix(path string) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	l, err := openListener(path, "unix", path)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return a.serve(binding{key: path, listener: l})
+}
+
+// RunInherited serves the listeners inherited through systemd socket activation
+// (LISTEN_FDS and LISTEN_PID) or from a parent process during Upgrade. Listeners are named
+// by LISTEN_FDNAMES, so routes can be restricted to them with ListenerGroup; use Serve
+// with matching names to configure TLS.
+func (a *App) RunInherited() error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	inherited := inheritedListeners()
+	if len(inherited) == 0 {
+		return errors.New("RunInherited: no inherited listeners")
+	}
+	bindings := make([]binding, len(inherited))
+	for i, il := range inherited {
+		key := il.name
+		if key == "" {
+			// Unnamed listeners are still handed on; RunInherited claims them all
+			key = "inherited"
+		}
+		bindings[i] = binding{key: key, name: il.name, listener: il.listener}
+	}
+	return a.serve(bindings...)
+}
+
+// Upgrade starts a new process from the running executable with the same arguments and hands
+// it the listeners of Run, RunTLS, RunUnix, RunInherited and Serve, which the new process picks
+// up again by address or name. Once it is serving, Upgrade shuts this App down with Shutdown so
+// in-flight requests drain while new connections go to the new process. If the new process does
+// not start serving before ctx is done it is killed and the App keeps serving.
+// Upgrade is only supported on Linux.
+func (a *App) Upgrade(ctx context.Context) error {
+	if err := a.handOff(ctx); err != nil {
+		return err
+	}
+	return a.Shutdown(ctx)
+}
+
+// namedListener is a listener inherited from systemd or a parent process.
+type namedListener struct {
+	name     string
+	listener net.Listener
+}
+
+// ListenerConfig describes a named listener started by Serve.
+type ListenerConfig struct {
+	// Name identifies the listener in WithListeners and ListenerGroup. An inherited
+	// listener with the same name is served instead of listening on Address.
+	Name string
+
+	// Network is "tcp" or "unix". Defaults to "tcp".
+	Network string
+
+	// Address to listen on, such as ":8080" or "/run/app.sock".
+	Address string
+
+	// Listener is served instead of listening on Address if set.
+	Listener net.Listener
+
+	// TLSConfig enables TLS for the listener. Defaults to ServerConfig.TLSConfig when
+	// CertFile and KeyFile are set.
+	TLSConfig *tls.Config
+
+	// CertFile and KeyFile enable TLS with the certificate loaded from the files.
+	CertFile string
+	KeyFile  string
+}
+
+// Serve starts every listener and blocks until Shutdown is called, a configured signal is
+// received or a listener fails, then shuts all of them down together. Each listener serves the
+// routes registered without WithListeners or ListenerGroup and those restricted to its name.
+//
+//	app.Serve(
+//		amaro.ListenerConfig{Name: "public", Address: ":443", CertFile: "cert.pem", KeyFile: "key.pem"},
+//		amaro.ListenerConfig{Name: "admin", Address: "127.0.0.1:9090"},
+//	)
+func (a *App) Serve(listeners ...ListenerConfig) error {
+	if len(listeners) == 0 {
+		return errors.New("Serve: no listeners")
+	}
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(listeners))
+	for _, lc := range listeners {
+		if names[lc.Name] {
+			return fmt.Errorf("Serve: duplicate listener %q", lc.Name)
+		}
+		names[lc.Name] = true
+	}
+	for _, route := range a.Routes() {
+		for _, name := range route.Listeners {
+			if !names[name] {
+				return fmt.Errorf("Serve: %s %s: unknown listener %q", route.Method, route.Path, name)
+			}
+		}
+	}
+
+	bindings := make([]binding, 0, len(listeners))
+	for _, lc := range listeners {
+		key := lc.Name
+		if key == "" {
+			key = lc.Address
+		}
+		l := lc.Listener
+		if l == nil {
+			network := lc.Network
+			if network == "" {
+				network = "tcp"
+			}
+			var err error
+			if l, err = openListener(key, network, lc.Address); err != nil {
+				for _, b := range bindings {
+					b.listener.Close()
+				}
+				return fmt.Errorf("server error: listener %q: %w", lc.Name, err)
+			}
+		}
+		bindings = append(bindings, binding{
+			key:       key,
+			name:      lc.Name,
+			listener:  l,
+			tls:       lc.TLSConfig != nil || (lc.CertFile != "" && lc.KeyFile != ""),
+			tlsConfig: lc.TLSConfig,
+			certFile:  lc.CertFile,
+			keyFile:   lc.KeyFile,
+		})
+	}
+	return a.serve(bindings...)
+}
+
+// openListener returns the inherited listener for key if there is one.
+// Otherwise it listens on address, removing a stale Unix socket file first.
+func openListener(key, network, address string) (net.Listener, error) {
+	if l := inheritedListener(key); l != nil {
+		return l, nil
+	}
+	if network == "unix" {
+		if info, err := os.Stat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(address)
+		}
+	}
+	return net.Listen(network, address)
+}
+
+func (a *App) listen(address, certFile, keyFile string) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+
+	if !strings.Contains(address, ":") {
+		address = ":" + address
+	}
+	l, err := openListener(address, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return a.serve(binding{
+		key:      address,
+		listener: l,
+		tls:      certFile != "" && keyFile != "",
+		certFile: certFile,
+		keyFile:  keyFile,
+	})
+}
+
+func (a *App) validate() error {
+	if err := a.Validate(); err != nil {
+		return fmt.Errorf("invalid routes: %w", err)
+	}
+	return nil
+}
+
+// binding is a listener and the server that serves it.
+// Listeners with a key are passed on by Upgrade and picked up again by openListener.
+type binding struct {
+	key               string
+	name              string
+	listener          net.Listener
+	server            *http.Server
+	tls               bool
+	tlsConfig         *tls.Config
+	certFile, keyFile string
+}
+
+type listenerKey struct{}
+
+// newServer returns an http.Server for b configured by a.server.
+func (a *App) newServer(b *binding) (*http.Server, error) {
+	config := a.server
+	srv := &http.Server{
+		Handler:           a,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		HTTP2:             config.HTTP2,
+		ErrorLog:          config.ErrorLog,
+	}
+	if b.tlsConfig != nil {
+		srv.TLSConfig = b.tlsConfig.Clone()
+	} else if config.TLSConfig != nil {
+		srv.TLSConfig = config.TLSConfig.Clone()
+	}
+	if b.name != "" {
+		ctx := context.WithValue(context.Background(), listenerKey{}, b.name)
+		srv.BaseContext = func(net.Listener) context.Context {
+			return ctx
+		}
+	}
+	if err := a.configureTLS(srv, b); err != nil {
+		return nil, err
+	}
+	if config.ConfigureServer != nil {
+		if err := config.ConfigureServer(srv, b.tls); err != nil {
+			return nil, err
+		}
+	}
+	return srv, nil
+}
+
+// serve runs the start hooks and serves every binding until Shutdown is called,
+// a configured signal is received or a server fails. The listeners are closed on return.
+func (a *App) serve(bindings ...binding) error {
+	a.setup() // Ensure middlewares are compiled before starting
+
+	closeListeners := func() {
+		for _, b := range bindings {
+			b.listener.Close()
+		}
+	}
+
+	a.lifecycle.mu.Lock()
+	hooks := a.lifecycle.onStart
+	a.lifecycle.mu.Unlock()
+	for _, hook := range hooks {
+		if err := hook(); err != nil {
+			closeListeners()
+			return fmt.Errorf("start hook: %w", err)
+		}
+	}
+
+	for i := range bindings {
+		if bindings[i].server == nil {
+			srv, err := a.newServer(&bindings[i])
+			if err != nil {
+				closeListeners()
+				return fmt.Errorf("server error: %w", err)
+			}
+			bindings[i].server = srv
+		}
+	}
+	if !a.lifecycle.register(bindings...) {
+		closeListeners()
+		return nil
+	}
+
+	// Buffered channel to receive OS signals.
+	var shutdown chan os.Signal
+	if len(a.server.Signals) > 0 {
+		shutdown = make(chan os.Signal, 1)
+		signal.Notify(shutdown, a.server.Signals...)
+		defer signal.Stop(shutdown)
+	}
+	var upgrade chan os.Signal
+	if len(a.server.UpgradeSignals) > 0 {
+		upgrade = make(chan os.Signal, 1)
+		signal.Notify(upgrade, a.server.UpgradeSignals...)
+		defer signal.Stop(upgrade)
+	}
+
+	// Channel to listen for errors coming from the listeners.
+	serverErrors := make(chan error, len(bindings))
+	for _, b := range bindings {
+		go func() {
+			if b.name != "" {
+				log.Printf("Server %s is starting on %s...", b.name, b.listener.Addr())
+			} else {
+				log.Printf("Server is starting on %s...", b.listener.Addr())
+			}
+			if b.tls {
+				serverErrors <- b.server.ServeTLS(b.listener, b.certFile, b.keyFile)
+			} else {
+				serverErrors <- b.server.Serve(b.listener)
+			}
+		}()
+	}
+	notifyReady()
+
+	// Block until a signal is received, Shutdown is called or an error occurs
+	for {
+		select {
+		case sig := <-upgrade:
+			log.Printf("upgrade started: signal %v", sig)
+
+			ctx, cancel := a.shutdownContext()
+			if err := a.handOff(ctx); err != nil {
+				cancel()
+				log.Printf("upgrade failed: %v", err)
+				continue
+			}
+			defer cancel()
+			if err := a.Shutdown(ctx); err != nil {
+				return fmt.Errorf("could not stop server gracefully: %w", err)
+			}
+			return nil
+
+		case err := <-serverErrors:
+			if errors.Is(err, http.ErrServerClosed) {
+				if !a.lifecycle.shuttingDown() {
+					// Closed without Shutdown, e.g. by srv.Close: stop the other servers and run the hooks
+					ctx, cancel := a.shutdownContext()
+					defer cancel()
+					if err := a.Shutdown(ctx); err != nil {
+						return fmt.Errorf("could not stop server gracefully: %w", err)
+					}
+				}
+				<-a.lifecycle.doneChan()
+				return nil
+			}
+
+			ctx, cancel := a.shutdownContext()
+			defer cancel()
+			a.Shutdown(ctx)
+			return fmt.Errorf("server error: %w", err)
+
+		case sig := <-shutdown:
+			log.Printf("shutdown started: signal %v", sig)
+
+			ctx, cancel := a.shutdownContext()
+			defer cancel()
+			if err := a.Shutdown(ctx); err != nil {
+				return fmt.Errorf("could not stop server gracefully: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+// shutdownContext returns the context for a shutdown started by the App itself.
+func (a *App) shutdownContext() (context.Context, context.CancelFunc) {
+	if a.server.ShutdownTimeout > 0 {
+		return context.WithTimeout(context.Background(), a.server.ShutdownTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,326 @@
+package amaro_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func newServerApp() *amaro.App {
+	config := amaro.DefaultServerConfig()
+	config.Signals = nil
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithServerConfig(config),
+	)
+	app.GET("/ping", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+	return app
+}
+
+func TestServerLifecycle(t *testing.T) {
+	app := newServerApp()
+
+	var events []string
+	started := make(chan struct{})
+	app.OnStart(func() error {
+		events = append(events, "start")
+		close(started)
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "workers")
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "db")
+		return errors.New("db: already closed")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Listener(l)
+	}()
+	<-started
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("Expected body %q, got %q", "pong", body)
+	}
+
+	if err := app.Shutdown(context.Background()); err == nil || !strings.Contains(err.Error(), "already closed") {
+		t.Errorf("Expected shutdown hook error, got %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Listener to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Listener did not return after Shutdown")
+	}
+
+	if got := strings.Join(events, ","); got != "start,workers,db" {
+		t.Errorf("Expected events start,workers,db, got %s", got)
+	}
+	if _, err := http.Get("http://" + l.Addr().String() + "/ping"); err == nil {
+		t.Error("Expected listener to be closed")
+	}
+}
+
+func TestServerShutdownBeforeStart(t *testing.T) {
+	app := newServerApp()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Listener(l); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestServerClosedWithoutShutdown(t *testing.T) {
+	config := amaro.DefaultServerConfig()
+	config.Signals = nil
+	servers := make(chan *http.Server, 1)
+	config.ConfigureServer = func(srv *http.Server, tls bool) error {
+		servers <- srv
+		return nil
+	}
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), amaro.WithServerConfig(config))
+
+	hooked := make(chan struct{})
+	app.OnShutdown(func(ctx context.Context) error {
+		close(hooked)
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Listener(l)
+	}()
+	(<-servers).Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Listener to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Listener did not return after the server was closed")
+	}
+	select {
+	case <-hooked:
+	default:
+		t.Error("Expected the shutdown hooks to run")
+	}
+}
+
+func TestServerStartHookError(t *testing.T) {
+	app := newServerApp()
+	app.OnStart(func() error {
+		return errors.New("database unreachable")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Listener(l); err == nil || !strings.Contains(err.Error(), "database unreachable") {
+		t.Errorf("Expected start hook error, got %v", err)
+	}
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	app := newServerApp()
+	path := filepath.Join(t.TempDir(), "amaro.sock")
+
+	started := make(chan struct{})
+	app.OnStart(func() error {
+		close(started)
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunUnix(path)
+	}()
+	<-started
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	app.Shutdown(context.Background())
+	if err := <-done; err != nil {
+		t.Errorf("Expected RunUnix to return nil, got %v", err)
+	}
+}
+
+func TestServeListeners(t *testing.T) {
+	app := newServerApp()
+	app.GET("/health", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "ok "+c.Listener())
+	})
+	public := app.ListenerGroup("public")
+	public.GET("/users", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+	admin := app.ListenerGroup("admin").Group("/debug")
+	admin.GET("/vars", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "vars")
+	})
+	app.Handle(http.MethodGet, "/metrics", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "metrics")
+	}, amaro.WithListeners("admin"))
+	app.Handle(http.MethodDelete, "/users", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "deleted")
+	}, amaro.WithListeners("admin"))
+
+	// Borrow a certificate and a client trusting it from httptest
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	tlsConfig := ts.TLS.Clone()
+	client := ts.Client()
+	ts.Close()
+
+	publicListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	app.OnStart(func() error {
+		close(started)
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Serve(
+			amaro.ListenerConfig{Name: "public", Listener: publicListener, TLSConfig: tlsConfig},
+			amaro.ListenerConfig{Name: "admin", Listener: adminListener},
+		)
+	}()
+	<-started
+
+	publicURL := "https://" + publicListener.Addr().String()
+	adminURL := "http://" + adminListener.Addr().String()
+	tests := []struct {
+		url  string
+		code int
+		body string
+	}{
+		{publicURL + "/health", http.StatusOK, "ok public"},
+		{adminURL + "/health", http.StatusOK, "ok admin"},
+		{publicURL + "/users", http.StatusOK, "users"},
+		{adminURL + "/users", http.StatusNotFound, ""},
+		{adminURL + "/debug/vars", http.StatusOK, "vars"},
+		{publicURL + "/debug/vars", http.StatusNotFound, ""},
+		{adminURL + "/metrics", http.StatusOK, "metrics"},
+		{publicURL + "/metrics", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		resp, err := client.Get(tt.url)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.url, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s: Expected status %d, got %d", tt.url, tt.code, resp.StatusCode)
+		}
+		if tt.body != "" && string(body) != tt.body {
+			t.Errorf("%s: Expected body %q, got %q", tt.url, tt.body, body)
+		}
+	}
+
+	// Methods of routes restricted to other listeners are not advertised
+	methods := []struct {
+		method string
+		url    string
+		code   int
+		allow  string
+	}{
+		{http.MethodPost, publicURL + "/users", http.StatusMethodNotAllowed, "GET, OPTIONS"},
+		{http.MethodOptions, publicURL + "/users", http.StatusNoContent, "GET, OPTIONS"},
+		{http.MethodPost, adminURL + "/users", http.StatusMethodNotAllowed, "DELETE, OPTIONS"},
+		{http.MethodOptions, publicURL + "/metrics", http.StatusNotFound, ""},
+	}
+	for _, tt := range methods {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s %s: Expected status %d, got %d", tt.method, tt.url, tt.code, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Expected Allow %q, got %q", tt.method, tt.url, tt.allow, got)
+		}
+	}
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected Serve to return nil, got %v", err)
+	}
+	if _, err := client.Get(adminURL + "/health"); err == nil {
+		t.Error("Expected admin listener to be closed")
+	}
+}
+
+func TestServeUnknownListener(t *testing.T) {
+	app := newServerApp()
+	app.ListenerGroup("admin").GET("/vars", func(c *amaro.Context) error {
+		return nil
+	})
+
+	err := app.Serve(amaro.ListenerConfig{Name: "public", Address: "127.0.0.1:0"})
+	if err == nil || !strings.Contains(err.Error(), `unknown listener "admin"`) {
+		t.Errorf("Expected unknown listener error, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package amaro
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Renderer renders named templates for Context.Render, see WithRenderer.
+// TemplateRenderer renders html/template templates; other engines can plug in by implementing it.
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, c *Context) error
+}
+
+// WithRenderer returns an AppOption that makes Context.Render use r.
+func WithRenderer(r Renderer) AppOption {
+	return func(app *App) {
+		app.renderer = r
+	}
+}
+
+// Render renders the named template with data using the App's Renderer and writes it with
+// statusCode. The Content-Type defaults to HTML. The template is rendered before anything is
+// written, so a template error can still be answered with an error page.
+func (c *Context) Render(statusCode int, name string, data interface{}) error {
+	if c.app == nil || c.app.renderer == nil {
+		return errors.New("Render: no Renderer configured, see WithRenderer")
+	}
+
+	var buf bytes.Buffer
+	if err := c.app.renderer.Render(&buf, name, data, c); err != nil {
+		return err
+	}
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+	c.Writer.WriteHeader(statusCode)
+	_, err := buf.WriteTo(c.Writer)
+	return err
+}
+
+// TemplateConfig defines configuration for a TemplateRenderer.
+//
+// Templates are named by their path in Root without the extension, e.g. "users/show" for
+// users/show.html. Templates in the Layouts and Partials directories are shared by all pages;
+// every other template is a page. A page is rendered inside Layout, which includes the blocks
+// the page defines:
+//
+//	<!-- layouts/base.html -->
+//	<title>{{block "title" .}}Amaro{{end}}</title>
+//	<main>{{block "content" .}}{{end}}</main>
+//
+//	<!-- users/show.html -->
+//	{{define "title"}}{{.Name}}{{end}}
+//	{{define "content"}}{{template "partials/avatar" .}} <a href="{{url "users.edit" .ID}}">Edit</a>{{end}}
+//
+// A single block of a page is rendered with "page#block", e.g. "users/show#content" for an htmx
+// fragment, and shared templates can be rendered by name, e.g. "partials/avatar".
+type TemplateConfig struct {
+	// Root is the filesystem holding the templates.
+	Root fs.FS
+
+	// Extension of the template files (default: ".html").
+	Extension string
+
+	// Layouts and Partials are the directories of the shared templates
+	// (default: "layouts" and "partials").
+	Layouts  string
+	Partials string
+
+	// Layout is the template pages are rendered in, e.g. "layouts/base".
+	// Pages are rendered on their own if empty.
+	Layout string
+
+	// Funcs are available to all templates, in addition to the built-in "url" and "asset".
+	// "url" builds the path of a named route like App.URL, in the App of the Context being
+	// rendered. "asset" returns the URL of a static file under AssetPrefix.
+	Funcs template.FuncMap
+
+	// AssetPrefix is the URL prefix static files are served under, e.g. "/static".
+	AssetPrefix string
+
+	// Assets, if set, is the filesystem of the static files. "asset" then appends a hash of
+	// the file's content to its URL, so browsers can cache it until it changes.
+	Assets fs.FS
+
+	// Reload parses the templates again on every render, so changes show up without a
+	// restart. Use it in development only.
+	Reload bool
+}
+
+// TemplateRenderer is a Renderer for html/template templates with layouts and partials.
+// It can be shared by several Apps: the templates are parsed once per App rendering them,
+// with "url" bound to that App.
+type TemplateRenderer struct {
+	config TemplateConfig
+
+	// mu guards the fields below.
+	mu     sync.RWMutex
+	sets   map[*App]*templateSet
+	hashes map[string]string // asset path -> content hash
+}
+
+// templateSet holds the parsed templates with "url" bound to one App.
+type templateSet struct {
+	pages  map[string]*template.Template
+	shared *template.Template
+}
+
+// NewTemplateRenderer parses the templates in config.Root. Parse errors are returned
+// immediately, also with Reload, so that broken templates fail at startup.
+func NewTemplateRenderer(config TemplateConfig) (*TemplateRenderer, error) {
+	if config.Root == nil {
+		return nil, errors.New("TemplateRenderer: Root is required")
+	}
+	if config.Extension == "" {
+		config.Extension = ".html"
+	}
+	if config.Layouts == "" {
+		config.Layouts = "layouts"
+	}
+	if config.Partials == "" {
+		config.Partials = "partials"
+	}
+
+	// The set without an App is parsed right away to report errors
+	r := &TemplateRenderer{config: config, hashes: map[string]string{}}
+	set, err := r.parse(nil)
+	if err != nil {
+		return nil, err
+	}
+	r.sets = map[*App]*templateSet{nil: set}
+	return r, nil
+}
+
+// Render implements Renderer.
+func (r *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c *Context) error {
+	var app *App
+	if c != nil {
+		app = c.app
+	}
+	t, block, err := r.lookup(name, app)
+	if err != nil {
+		return err
+	}
+	return t.ExecuteTemplate(w, block, data)
+}
+
+// lookup returns the template set of app for name and the template to execute in it.
+func (r *TemplateRenderer) lookup(name string, app *App) (*template.Template, string, error) {
+	set, err := r.set(app)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, block, hasBlock := strings.Cut(name, "#")
+	if t, ok := set.pages[page]; ok {
+		switch {
+		case hasBlock:
+			if t.Lookup(block) == nil {
+				return nil, "", fmt.Errorf("template %q: no block %q", page, block)
+			}
+			return t, block, nil
+		case r.config.Layout != "":
+			return t, r.config.Layout, nil
+		default:
+			return t, page, nil
+		}
+	}
+	if !hasBlock && set.shared.Lookup(name) != nil {
+		return set.shared, name, nil
+	}
+	return nil, "", fmt.Errorf("template %q not found", name)
+}
+
+// set returns the templates whose "url" builds paths in app, parsing them on the first
+// render by app, or on every render with Reload.
+func (r *TemplateRenderer) set(app *App) (*templateSet, error) {
+	if !r.config.Reload {
+		r.mu.RLock()
+		set, ok := r.sets[app]
+		r.mu.RUnlock()
+		if ok {
+			return set, nil
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if set, ok := r.sets[app]; ok && !r.config.Reload {
+		return set, nil
+	}
+	set, err := r.parse(app)
+	if err != nil {
+		return nil, err
+	}
+	if r.config.Reload {
+		// Assets may have changed as well
+		r.hashes = map[string]string{}
+	}
+	r.sets[app] = set
+	return set, nil
+}
+
+// parse parses all templates with "url" bound to app.
+func (r *TemplateRenderer) parse(app *App) (*templateSet, error) {
+	sources := map[string]string{}
+	var sharedNames, pageNames []string
+	err := fs.WalkDir(r.config.Root, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(file) != r.config.Extension {
+			return err
+		}
+		data, err := fs.ReadFile(r.config.Root, file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(file, r.config.Extension)
+		sources[name] = string(data)
+		if dir, _, _ := strings.Cut(file, "/"); dir == r.config.Layouts || dir == r.config.Partials {
+			sharedNames = append(sharedNames, name)
+		} else {
+			pageNames = append(pageNames, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := r.funcs(app)
+	parseShared := func(t *template.Template) error {
+		for _, name := range sharedNames {
+			if _, err := t.New(name).Parse(sources[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	shared := template.New("").Funcs(funcs)
+	if err := parseShared(shared); err != nil {
+		return nil, err
+	}
+	if r.config.Layout != "" && shared.Lookup(r.config.Layout) == nil {
+		return nil, fmt.Errorf("layout %q not found", r.config.Layout)
+	}
+
+	pages := make(map[string]*template.Template, len(pageNames))
+	for _, name := range pageNames {
+		// Shared templates are parsed first so that the blocks a page defines override theirs
+		t := template.New(name).Funcs(funcs)
+		if err := parseShared(t); err != nil {
+			return nil, err
+		}
+		if _, err := t.Parse(sources[name]); err != nil {
+			return nil, err
+		}
+		pages[name] = t
+	}
+
+	return &templateSet{pages: pages, shared: shared}, nil
+}
+
+// funcs returns the configured template funcs with the built-in ones, "url" building paths in app.
+func (r *TemplateRenderer) funcs(app *App) template.FuncMap {
+	funcs := template.FuncMap{
+		"url": func(name string, params ...interface{}) (string, error) {
+			if app == nil {
+				return "", errors.New("url: template is not rendered through an App, see Context.Render")
+			}
+			return app.URL(name, params...)
+		},
+		"asset": r.asset,
+	}
+	for name, fn := range r.config.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// asset returns the URL of the static file at file, with a content hash if Assets is set.
+func (r *TemplateRenderer) asset(file string) (string, error) {
+	file = strings.TrimPrefix(file, "/")
+	url := strings.TrimSuffix(r.config.AssetPrefix, "/") + "/" + file
+	if r.config.Assets == nil {
+		return url, nil
+	}
+
+	// Called while executing, so r.mu is not held
+	r.mu.RLock()
+	hash, ok := r.hashes[file]
+	r.mu.RUnlock()
+	if !ok {
+		data, err := fs.ReadFile(r.config.Assets, file)
+		if err != nil {
+			return "", fmt.Errorf("asset: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		hash = hex.EncodeToString(sum[:4])
+		r.mu.Lock()
+		r.hashes[file] = hash
+		r.mu.Unlock()
+	}
+	return url + "?v=" + hash, nil
+}
@@ -0,0 +1,183 @@
+package amaro_test
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func newTemplateFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(
+			`<title>{{block "title" .}}Amaro{{end}}</title><link href="{{asset "css/app.css"}}">` +
+				`<main>{{block "content" .}}{{end}}</main>`)},
+		"partials/user.html": {Data: []byte(`<li>{{shout .Name}}</li>`)},
+		"users/show.html": {Data: []byte(
+			`{{define "title"}}{{.Name}}{{end}}` +
+				`{{define "content"}}{{template "partials/user" .}}<a href="{{url "users.edit" .ID}}">edit</a>{{end}}`)},
+		"about.html": {Data: []byte(`{{define "content"}}about{{end}}`)},
+	}
+}
+
+func newTemplateApp(t *testing.T, config amaro.TemplateConfig) *amaro.App {
+	t.Helper()
+	config.Layout = "layouts/base"
+	config.Funcs = template.FuncMap{"shout": strings.ToUpper}
+	config.AssetPrefix = "/static"
+	config.Assets = fstest.MapFS{"css/app.css": {Data: []byte("body{}")}}
+	renderer, err := amaro.NewTemplateRenderer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), amaro.WithRenderer(renderer))
+	app.Handle(http.MethodGet, "/users/:id/edit", func(c *amaro.Context) error { return nil }, amaro.WithName("users.edit"))
+	app.GET("/render/*name", func(c *amaro.Context) error {
+		return c.Render(http.StatusOK, c.PathParam("name"), map[string]any{"ID": 7, "Name": "ada"})
+	})
+	return app
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	app := newTemplateApp(t, amaro.TemplateConfig{Root: newTemplateFS()})
+
+	tests := []struct {
+		name string
+		code int
+		body string
+	}{
+		{"users/show", http.StatusOK,
+			`<title>ada</title><link href="/static/css/app.css\?v=[0-9a-f]{8}">` +
+				`<main><li>ADA</li><a href="/users/7/edit">edit</a></main>`},
+		{"about", http.StatusOK, `<title>Amaro</title>.*<main>about</main>`},
+		{"users/show#content", http.StatusOK, `^<li>ADA</li><a href="/users/7/edit">edit</a>$`},
+		{"partials/user", http.StatusOK, `^<li>ADA</li>$`},
+		{"users/missing", http.StatusInternalServerError, `template "users/missing" not found`},
+		{"users/show#sidebar", http.StatusInternalServerError, `no block "sidebar"`},
+	}
+	for _, tt := range tests {
+		w := app.Test(httptest.NewRequest(http.MethodGet, "/render/"+tt.name, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: Expected status %d, got %d", tt.name, tt.code, w.Code)
+		}
+		if !regexp.MustCompile(tt.body).MatchString(w.Body.String()) {
+			t.Errorf("%s: Expected body to match %q, got %q", tt.name, tt.body, w.Body.String())
+		}
+		if tt.code == http.StatusOK && w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Errorf("%s: Expected HTML content type, got %q", tt.name, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestTemplateRendererReload(t *testing.T) {
+	root := newTemplateFS()
+	app := newTemplateApp(t, amaro.TemplateConfig{Root: root, Reload: true})
+
+	render := func() string {
+		w := app.Test(httptest.NewRequest(http.MethodGet, "/render/about", nil))
+		return w.Body.String()
+	}
+	if body := render(); !strings.Contains(body, "<main>about</main>") {
+		t.Fatalf("Unexpected body %q", body)
+	}
+	root["about.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}about us{{end}}`)}
+	if body := render(); !strings.Contains(body, "<main>about us</main>") {
+		t.Errorf("Expected reloaded template, got %q", body)
+	}
+}
+
+func TestTemplateRendererConcurrent(t *testing.T) {
+	for _, reload := range []bool{false, true} {
+		app := newTemplateApp(t, amaro.TemplateConfig{Root: newTemplateFS(), Reload: reload})
+		app.Test(httptest.NewRequest(http.MethodGet, "/render/about", nil)) // builds the handler chain
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := app.Test(httptest.NewRequest(http.MethodGet, "/render/users/show", nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("Reload %v: Expected 200, got %d", reload, w.Code)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestTemplateRendererSharedByApps(t *testing.T) {
+	renderer, err := amaro.NewTemplateRenderer(amaro.TemplateConfig{Root: fstest.MapFS{
+		"link.html": {Data: []byte(`{{url "users.edit" 7}}`)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newApp := func(prefix string) *amaro.App {
+		app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), amaro.WithRenderer(renderer))
+		app.Handle(http.MethodGet, prefix+"/users/:id/edit", func(c *amaro.Context) error { return nil }, amaro.WithName("users.edit"))
+		app.GET("/link", func(c *amaro.Context) error {
+			return c.Render(http.StatusOK, "link", nil)
+		})
+		return app
+	}
+	site, admin := newApp(""), newApp("/admin")
+
+	// Each App builds the path from its own routes, whichever was configured last
+	for _, tt := range []struct {
+		app  *amaro.App
+		body string
+	}{
+		{site, "/users/7/edit"},
+		{admin, "/admin/users/7/edit"},
+		{site, "/users/7/edit"},
+	} {
+		w := tt.app.Test(httptest.NewRequest(http.MethodGet, "/link", nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("Expected %q, got %q", tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestTemplateRendererParseError(t *testing.T) {
+	root := newTemplateFS()
+	root["broken.html"] = &fstest.MapFile{Data: []byte(`{{if}}`)}
+	if _, err := amaro.NewTemplateRenderer(amaro.TemplateConfig{Root: root}); err == nil {
+		t.Error("Expected parse error")
+	}
+	if _, err := amaro.NewTemplateRenderer(amaro.TemplateConfig{Root: newTemplateFS(), Layout: "layouts/missing"}); err == nil {
+		t.Error("Expected missing layout error")
+	}
+}
+
+type stubRenderer struct{}
+
+func (stubRenderer) Render(w io.Writer, name string, data interface{}, c *amaro.Context) error {
+	_, err := io.WriteString(w, name+" for "+c.Request.URL.Path)
+	return err
+}
+
+func TestCustomRenderer(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()), amaro.WithRenderer(stubRenderer{}))
+	app.GET("/", func(c *amaro.Context) error {
+		c.SetHeader("Content-Type", "text/plain")
+		return c.Render(http.StatusAccepted, "home", nil)
+	})
+
+	w := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "home for /" {
+		t.Errorf("Expected 202 %q, got %d %q", "home for /", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected Content-Type to be kept, got %q", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,149 @@
+package amaro
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves a certificate loaded from a certificate and key file and reloads it
+// when either file changes, so certificates can be rotated without a restart.
+// Use its GetCertificate method as tls.Config.GetCertificate.
+type CertReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// NewCertReloader loads the certificate from certFile and keyFile. The files are checked for
+// changes at most once per interval, on the next TLS handshake.
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if the files have changed.
+// If the new files cannot be loaded, for example while only one of them has been replaced,
+// the previous certificate is kept and loading is retried after the interval.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert, nil
+	}
+	r.checked = now
+
+	modTime, err := r.latestModTime()
+	if err == nil && !modTime.Equal(r.modTime) {
+		err = r.load(modTime)
+	}
+	if err != nil {
+		log.Printf("certificate reload failed: %v", err)
+	}
+	return r.cert, nil
+}
+
+// Reload loads the certificate from the files immediately, e.g. from a SIGHUP handler.
+func (r *CertReloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	r.checked = time.Now()
+	return r.load(modTime)
+}
+
+// latestModTime returns the latest modification time of the certificate and key file.
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// LoadCertPool returns a certificate pool with the PEM encoded certificates in file,
+// e.g. a CA bundle for verifying client certificates.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", file)
+	}
+	return pool, nil
+}
+
+// configureTLS sets up certificate reloading and client certificate verification for b
+// as configured by a.server. Certificate files handled by a CertReloader are cleared from b
+// so that ServeTLS does not load them again.
+func (a *App) configureTLS(srv *http.Server, b *binding) error {
+	config := a.server
+	if !b.tls || (config.CertReloadInterval <= 0 && config.ClientCAFile == "") {
+		return nil
+	}
+	if srv.TLSConfig == nil {
+		srv.TLSConfig = &tls.Config{}
+	}
+
+	if config.CertReloadInterval > 0 && b.certFile != "" && b.keyFile != "" {
+		reloader, err := NewCertReloader(b.certFile, b.keyFile, config.CertReloadInterval)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig.GetCertificate = reloader.GetCertificate
+		b.certFile, b.keyFile = "", ""
+	}
+
+	if config.ClientCAFile != "" {
+		pool, err := LoadCertPool(config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("client CA: %w", err)
+		}
+		srv.TLSConfig.ClientCAs = pool
+		srv.TLSConfig.ClientAuth = config.ClientAuth
+		if srv.TLSConfig.ClientAuth == tls.NoClientCert {
+			srv.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return nil
+}
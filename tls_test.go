@@ -0,0 +1,231 @@
+package amaro_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/middlewares"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+// testCert is a certificate issued by a test CA.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	tls  tls.Certificate
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, issuer *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{
+		cert: cert,
+		key:  key,
+		tls:  tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert},
+	}
+}
+
+// write stores the certificate and key as PEM files in dir.
+func (c *testCert) write(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", c.cert.Raw)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	// Write and rename, as certificate managers do, so a reload never sees a partial file
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", ca.cert.Raw)
+
+	serverCert := func(name string) *testCert {
+		return newTestCert(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: name},
+			IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}, ca)
+	}
+	certFile, keyFile := serverCert("server-1").write(t, dir)
+	client := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "billing", Organization: []string{"Acme"}},
+		DNSNames:    []string{"billing.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	config := amaro.DefaultServerConfig()
+	config.Signals = nil
+	config.CertReloadInterval = time.Millisecond
+	config.ClientCAFile = caFile
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithServerConfig(config),
+	)
+	app.GET("/whoami", func(c *amaro.Context) error {
+		cert := middlewares.GetClientCert(c)
+		return c.String(http.StatusOK, cert.Subject.CommonName+" "+cert.DNSNames[0])
+	}, middlewares.ClientCertAuth(func(cert *middlewares.ClientCert, c *amaro.Context) (bool, error) {
+		return len(cert.Subject.Organization) > 0 && cert.Subject.Organization[0] == "Acme", nil
+	}))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	app.OnStart(func() error {
+		close(started)
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Serve(amaro.ListenerConfig{Listener: l, CertFile: certFile, KeyFile: keyFile})
+	}()
+	<-started
+	defer func() {
+		app.Shutdown(context.Background())
+		<-done
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	url := "https://" + l.Addr().String() + "/whoami"
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+		return client.Get(url)
+	}
+
+	t.Run("ClientCertificate", func(t *testing.T) {
+		resp, err := get(client.tls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "billing billing.internal" {
+			t.Errorf("Expected %q, got %q", "billing billing.internal", body)
+		}
+		if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != "server-1" {
+			t.Errorf("Expected server certificate server-1, got %s", cn)
+		}
+	})
+
+	t.Run("NoClientCertificate", func(t *testing.T) {
+		if resp, err := get(); err == nil {
+			resp.Body.Close()
+			t.Error("Expected handshake to fail without a client certificate")
+		}
+	})
+
+	t.Run("UntrustedClientCertificate", func(t *testing.T) {
+		other := newTestCert(t, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "billing", Organization: []string{"Acme"}},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, nil)
+		if resp, err := get(other.tls); err == nil {
+			resp.Body.Close()
+			t.Error("Expected handshake to fail with a self-signed client certificate")
+		}
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		serverCert("server-2").write(t, dir)
+		// Make sure the modification time differs on filesystems with coarse timestamps
+		later := time.Now().Add(time.Second)
+		os.Chtimes(certFile, later, later)
+
+		time.Sleep(5 * time.Millisecond)
+		resp, err := get(client.tls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != "server-2" {
+			t.Errorf("Expected reloaded certificate server-2, got %s", cn)
+		}
+	})
+}
+
+func TestCertReloaderKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "server"}}, nil)
+	certFile, keyFile := cert.write(t, dir)
+
+	reloader, err := amaro.NewCertReloader(certFile, keyFile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A certificate without its matching key, as seen halfway through a rotation
+	other := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "other"}}, nil)
+	writePEM(t, certFile, "CERTIFICATE", other.cert.Raw)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(certFile, later, later)
+
+	if err := reloader.Reload(); err == nil {
+		t.Error("Expected Reload to fail with mismatched key")
+	}
+	got, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Leaf == nil || got.Leaf.Subject.CommonName != "server" {
+		t.Errorf("Expected previous certificate to be kept")
+	}
+}
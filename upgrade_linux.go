@@ -0,0 +1,248 @@
+//go:build linux
+
+package amaro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Environment of the socket activation protocol described in sd_listen_fds(3).
+// A process started by Upgrade cannot be given its own pid in LISTEN_PID, so it gets its
+// parent's pid in upgradeEnv instead, escaped listener keys in LISTEN_FDNAMES and a pipe
+// to report readiness on following the listeners.
+const (
+	listenPIDEnv     = "LISTEN_PID"
+	listenFDsEnv     = "LISTEN_FDS"
+	listenFDNamesEnv = "LISTEN_FDNAMES"
+	upgradeEnv       = "AMARO_UPGRADE_PPID"
+	listenFDsStart   = 3
+)
+
+// inherited holds the listeners passed to this process, claimed one by one by openListener.
+var inherited struct {
+	once      sync.Once
+	mu        sync.Mutex
+	listeners []namedListener
+	ready     *os.File
+}
+
+// loadInherited picks up the inherited file descriptors on first use and clears the
+// environment so that they are not passed on to child processes.
+func loadInherited() {
+	inherited.once.Do(func() {
+		n, err := strconv.Atoi(os.Getenv(listenFDsEnv))
+		if err != nil || n <= 0 {
+			return
+		}
+
+		upgrade := false
+		if pid, err := strconv.Atoi(os.Getenv(listenPIDEnv)); err == nil {
+			if pid != os.Getpid() {
+				return
+			}
+		} else if ppid, err := strconv.Atoi(os.Getenv(upgradeEnv)); err == nil && ppid == os.Getppid() {
+			upgrade = true
+		} else {
+			return
+		}
+
+		names := strings.Split(os.Getenv(listenFDNamesEnv), ":")
+		for _, key := range []string{listenPIDEnv, listenFDsEnv, listenFDNamesEnv, upgradeEnv} {
+			os.Unsetenv(key)
+		}
+
+		for i := 0; i < n; i++ {
+			fd := listenFDsStart + i
+			syscall.CloseOnExec(fd)
+
+			var name string
+			if i < len(names) {
+				name = names[i]
+				if upgrade {
+					name, _ = url.PathUnescape(name)
+				}
+			}
+
+			f := os.NewFile(uintptr(fd), name)
+			l, err := net.FileListener(f)
+			f.Close()
+			if err != nil {
+				log.Printf("inherited fd %d: %v", fd, err)
+				continue
+			}
+			inherited.listeners = append(inherited.listeners, namedListener{name: name, listener: l})
+		}
+
+		if upgrade {
+			fd := listenFDsStart + n
+			syscall.CloseOnExec(fd)
+			inherited.ready = os.NewFile(uintptr(fd), "ready")
+		}
+	})
+}
+
+// inheritedListener claims the inherited listener named key, or returns nil.
+func inheritedListener(key string) net.Listener {
+	loadInherited()
+	if key == "" {
+		return nil
+	}
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	for i, il := range inherited.listeners {
+		if il.name == key {
+			inherited.listeners = slices.Delete(inherited.listeners, i, i+1)
+			return il.listener
+		}
+	}
+	return nil
+}
+
+// inheritedListeners claims all remaining inherited listeners.
+func inheritedListeners() []namedListener {
+	loadInherited()
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	listeners := inherited.listeners
+	inherited.listeners = nil
+	return listeners
+}
+
+// notifyReady tells the parent process of an upgrade that this one is serving,
+// once all inherited listeners have been claimed.
+func notifyReady() {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	if inherited.ready == nil || len(inherited.listeners) > 0 {
+		return
+	}
+	inherited.ready.Write([]byte{1})
+	inherited.ready.Close()
+	inherited.ready = nil
+}
+
+// handOff starts the new process of an upgrade with the App's listeners and waits until it is serving.
+func (a *App) handOff(ctx context.Context) error {
+	a.lifecycle.mu.Lock()
+	bindings := slices.Clone(a.lifecycle.bindings)
+	a.lifecycle.mu.Unlock()
+
+	// Listeners are passed as duplicates of their descriptors. Going through os.File.Fd,
+	// as os/exec does, would switch the shared descriptions to blocking mode and leave
+	// this process stuck in accept during the shutdown that follows.
+	var fds []uintptr
+	var names []string
+	defer func() {
+		for _, fd := range fds {
+			syscall.Close(int(fd))
+		}
+	}()
+	for _, b := range bindings {
+		sc, ok := b.listener.(syscall.Conn)
+		if b.key == "" || !ok {
+			continue
+		}
+		fd, err := dupListener(sc)
+		if err != nil {
+			return fmt.Errorf("upgrade: %w", err)
+		}
+		fds = append(fds, fd)
+		names = append(names, url.PathEscape(b.key))
+	}
+	if len(fds) == 0 {
+		return errors.New("upgrade: no listeners to hand off")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+	defer r.Close()
+
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		key, _, _ := strings.Cut(kv, "=")
+		return key == listenPIDEnv || key == listenFDsEnv || key == listenFDNamesEnv || key == upgradeEnv
+	})
+	env = append(env,
+		listenFDsEnv+"="+strconv.Itoa(len(fds)),
+		listenFDNamesEnv+"="+strings.Join(names, ":"),
+		upgradeEnv+"="+strconv.Itoa(os.Getpid()),
+	)
+
+	files := []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()}
+	files = append(files, fds...)
+	files = append(files, w.Fd())
+	pid, err := syscall.ForkExec(executable, os.Args, &syscall.ProcAttr{Env: env, Files: files})
+	w.Close()
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		var b [1]byte
+		_, err := r.Read(b[:])
+		ready <- err
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			process.Wait()
+			return fmt.Errorf("upgrade: process %d exited before serving", pid)
+		}
+	case <-ctx.Done():
+		process.Kill()
+		process.Wait()
+		return fmt.Errorf("upgrade: process %d not serving: %w", pid, ctx.Err())
+	}
+
+	// The new process serves the listeners now, keep Unix socket files around for it
+	for _, b := range bindings {
+		if ul, ok := b.listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	log.Printf("upgrade: process %d is serving", pid)
+	return process.Release()
+}
+
+// dupListener duplicates the descriptor of a listener with close-on-exec set.
+func dupListener(sc syscall.Conn) (uintptr, error) {
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd uintptr
+	var errno syscall.Errno
+	err = rc.Control(func(s uintptr) {
+		fd, _, errno = syscall.Syscall(syscall.SYS_FCNTL, s, syscall.F_DUPFD_CLOEXEC, 0)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if errno != 0 {
+		return 0, errno
+	}
+	return fd, nil
+}
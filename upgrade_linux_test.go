@@ -0,0 +1,176 @@
+package amaro_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+// TestUpgradeHelper is the server process started by the tests below.
+func TestUpgradeHelper(t *testing.T) {
+	mode := os.Getenv("AMARO_TEST_HELPER")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+
+	config := amaro.DefaultServerConfig()
+	config.UpgradeSignals = []os.Signal{syscall.SIGUSR2}
+	app := amaro.New(
+		amaro.WithRouter(routers.NewTrieRouter()),
+		amaro.WithServerConfig(config),
+	)
+	app.GET("/pid", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, strconv.Itoa(os.Getpid())+" "+c.Listener())
+	})
+	app.GET("/slow", func(c *amaro.Context) error {
+		time.Sleep(500 * time.Millisecond)
+		return c.String(http.StatusOK, "slow")
+	})
+
+	var err error
+	switch mode {
+	case "serve":
+		err = app.Serve(amaro.ListenerConfig{Name: "public", Address: os.Getenv("AMARO_TEST_ADDR")})
+	case "inherited":
+		err = app.RunInherited()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func helperCommand(mode string, env ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestUpgradeHelper$")
+	cmd.Env = append(os.Environ(), "AMARO_TEST_HELPER="+mode)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// getPID polls url until the server answers and returns the pid and listener it reports.
+func getPID(t *testing.T, url string, not int) (int, string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(url + "/pid")
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			pid, name, _ := strings.Cut(string(body), " ")
+			if n, _ := strconv.Atoi(pid); n != 0 && n != not {
+				return n, name
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("no server answered on %s", url)
+	return 0, ""
+}
+
+func TestUpgrade(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	url := "http://" + addr
+
+	parent := helperCommand("serve", "AMARO_TEST_ADDR="+addr)
+	if err := parent.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- parent.Wait()
+	}()
+
+	pid, name := getPID(t, url, 0)
+	if pid != parent.Process.Pid || name != "public" {
+		t.Fatalf("Expected pid %d on public, got %d on %q", parent.Process.Pid, pid, name)
+	}
+
+	// An in-flight request survives the upgrade
+	slow := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		slow <- string(body)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	parent.Process.Signal(syscall.SIGUSR2)
+
+	child, name := getPID(t, url, pid)
+	t.Cleanup(func() {
+		syscall.Kill(child, syscall.SIGTERM)
+	})
+	if name != "public" {
+		t.Errorf("Expected child to serve public, got %q", name)
+	}
+	if body := <-slow; body != "slow" {
+		t.Errorf("Expected in-flight request to complete, got %q", body)
+	}
+
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Errorf("Expected parent to exit cleanly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		parent.Process.Kill()
+		t.Fatal("parent did not exit after upgrade")
+	}
+
+	if pid, _ := getPID(t, url, 0); pid != child {
+		t.Errorf("Expected child %d to keep serving, got %d", child, pid)
+	}
+}
+
+func TestSocketActivation(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// LISTEN_PID must be the pid of the server, which the shell keeps across exec
+	cmd := helperCommand("inherited", "LISTEN_FDS=1", "LISTEN_FDNAMES=admin")
+	cmd.Args = append([]string{"/bin/sh", "-c", `LISTEN_PID=$$ exec "$0" "$@"`}, cmd.Args...)
+	cmd.Path = "/bin/sh"
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	pid, name := getPID(t, "http://"+l.Addr().String(), 0)
+	if pid != cmd.Process.Pid || name != "admin" {
+		t.Errorf("Expected pid %d on admin, got %d on %q", cmd.Process.Pid, pid, name)
+	}
+
+	cmd.Process.Signal(syscall.SIGTERM)
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("Expected clean exit, got %v", err)
+	}
+}
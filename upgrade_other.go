@@ -0,0 +1,24 @@
+//go:build !linux
+
+package amaro
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+)
+
+func inheritedListener(key string) net.Listener {
+	return nil
+}
+
+func inheritedListeners() []namedListener {
+	return nil
+}
+
+func notifyReady() {}
+
+func (a *App) handOff(ctx context.Context) error {
+	return errors.New("upgrade: not supported on " + runtime.GOOS)
+}
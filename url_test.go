@@ -0,0 +1,199 @@
+package amaro_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestNamedRoutes(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error { return nil }
+
+	app.Handle(http.MethodGet, "/users/:id", handler, amaro.WithName("user.show"))
+	app.Handle(http.MethodGet, "/orgs/{org}/repos/{repo}", handler, amaro.WithName("repo.show"))
+	app.Handle(http.MethodGet, "/assets/*filepath", handler, amaro.WithName("assets"))
+
+	api := app.Group("/api")
+	api.Handle(http.MethodGet, "/items/:id", handler, amaro.WithName("api.item"))
+
+	cases := []struct {
+		name   string
+		params []interface{}
+		want   string
+	}{
+		{"user.show", []interface{}{42}, "/users/42"},
+		{"repo.show", []interface{}{"acme", "web app"}, "/orgs/acme/repos/web%20app"},
+		{"assets", []interface{}{"css/main.css"}, "/assets/css/main.css"},
+		{"api.item", []interface{}{"7"}, "/api/items/7"},
+	}
+
+	for _, tc := range cases {
+		got, err := app.URL(tc.name, tc.params...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := app.URL("user.show"); err == nil {
+		t.Error("Expected error for missing param")
+	}
+	if _, err := app.URL("user.show", 1, 2); err == nil {
+		t.Error("Expected error for extra param")
+	}
+	if _, err := app.URL("missing"); err == nil {
+		t.Error("Expected error for unknown route name")
+	}
+}
+
+func TestContextURLFor(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+
+	app.Handle(http.MethodGet, "/posts/:slug", func(c *amaro.Context) error {
+		return nil
+	}, amaro.WithName("post"))
+
+	app.GET("/go", func(c *amaro.Context) error {
+		url, err := c.URLFor("post", "hello")
+		if err != nil {
+			return err
+		}
+		return c.Redirect(http.StatusFound, url)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/go", nil)
+	w := app.Test(req)
+
+	if w.Code != http.StatusFound {
+		t.Errorf("Expected 302, got %d", w.Code)
+	}
+	if got := w.Header().Get("Location"); got != "/posts/hello" {
+		t.Errorf("Expected Location /posts/hello, got %s", got)
+	}
+}
+
+func TestNamedRoutes_OptionalAndMultiParam(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error { return nil }
+
+	app.Handle(http.MethodGet, "/posts/:page?", handler, amaro.WithName("posts"))
+	app.Handle(http.MethodGet, "/files/:name.:ext", handler, amaro.WithName("file"))
+
+	cases := []struct {
+		name   string
+		params []interface{}
+		want   string
+	}{
+		{"posts", nil, "/posts"},
+		{"posts", []interface{}{2}, "/posts/2"},
+		{"file", []interface{}{"report", "pdf"}, "/files/report.pdf"},
+	}
+
+	for _, tc := range cases {
+		got, err := app.URL(tc.name, tc.params...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestNamedRoutes_LateRegistration(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error { return nil }
+
+	app.Handle(http.MethodGet, "/users/:id", handler, amaro.WithName("user.show"))
+	if _, err := app.URL("post.show", "hello"); err == nil {
+		t.Fatal("Expected error for unknown route name")
+	}
+
+	app.Group("/blog").Handle(http.MethodGet, "/:slug", handler, amaro.WithName("post.show"))
+	if got, err := app.URL("post.show", "hello"); err != nil || got != "/blog/hello" {
+		t.Errorf("Expected /blog/hello, got %q, %v", got, err)
+	}
+
+	// Routes registered on the router directly are seen too
+	router := routers.NewTrieRouter()
+	direct := amaro.New(amaro.WithRouter(router))
+	if _, err := direct.URL("tag.show", "go"); err == nil {
+		t.Fatal("Expected error for unknown route name")
+	}
+	router.AddRoute(amaro.Route{Method: http.MethodGet, Path: "/tags/:tag", Name: "tag.show", Handler: handler})
+	if got, err := direct.URL("tag.show", "go"); err != nil || got != "/tags/go" {
+		t.Errorf("Expected /tags/go, got %q, %v", got, err)
+	}
+}
+
+func TestNamedRoutes_MethodHelpers(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	mw := func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			c.SetHeader("X-Middleware", "1")
+			return next(c)
+		}
+	}
+
+	err := app.GET("/users/:id", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "user")
+	}, amaro.Middleware(mw), amaro.WithName("user.show"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Group("/api").POST("/items", func(c *amaro.Context) error { return nil }, amaro.WithName("item.create"))
+
+	if got, err := app.URL("user.show", 7); err != nil || got != "/users/7" {
+		t.Errorf("Expected /users/7, got %q, %v", got, err)
+	}
+	if got, err := app.URL("item.create"); err != nil || got != "/api/items" {
+		t.Errorf("Expected /api/items, got %q, %v", got, err)
+	}
+
+	w := app.Test(httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	if w.Code != http.StatusOK || w.Header().Get("X-Middleware") != "1" {
+		t.Errorf("Expected 200 with middleware, got %d %q", w.Code, w.Header().Get("X-Middleware"))
+	}
+}
+
+// plainRouter hides the AddRoute method of the router it wraps.
+type plainRouter struct {
+	amaro.Router
+}
+
+func TestHandleWithoutRouteAdder(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(plainRouter{routers.NewTrieRouter()}))
+	mw := func(next amaro.Handler) amaro.Handler {
+		return func(c *amaro.Context) error {
+			c.SetHeader("X-Middleware", "1")
+			return next(c)
+		}
+	}
+	app.Handle(http.MethodGet, "/ping", func(c *amaro.Context) error {
+		return c.String(http.StatusOK, "pong")
+	}, amaro.WithMiddlewares(mw))
+
+	w := app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "pong" || w.Header().Get("X-Middleware") != "1" {
+		t.Errorf("Expected 200 pong with middleware, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Names and metadata would be dropped by Add
+	err := app.GET("/users/:id", func(c *amaro.Context) error { return nil }, amaro.WithName("user.show"))
+	if !errors.Is(err, amaro.ErrRouteOptionsUnsupported) {
+		t.Errorf("Expected ErrRouteOptionsUnsupported for a named route, got %v", err)
+	}
+	err = app.GET("/users", func(c *amaro.Context) error { return nil }, amaro.WithTags("users"))
+	if !errors.Is(err, amaro.ErrRouteOptionsUnsupported) {
+		t.Errorf("Expected ErrRouteOptionsUnsupported for route metadata, got %v", err)
+	}
+}
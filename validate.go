@@ -0,0 +1,454 @@
+package amaro
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError describes a field that failed a rule of its validate tag.
+type FieldError struct {
+	// Field is the path of the field from the validated struct, e.g. "Name",
+	// "Address.Street", "Tags[2]" or "Labels[env]".
+	Field string
+
+	// Rule is the name of the failed rule, e.g. "min", and Param its parameter, e.g. "2".
+	Rule  string
+	Param string
+
+	// Value is the value of the field.
+	Value interface{}
+}
+
+// Error returns the message for the rule in DefaultValidationMessages.
+func (e *FieldError) Error() string {
+	return e.Translate(nil)
+}
+
+// Translate returns the message for the rule in messages, falling back to
+// DefaultValidationMessages. Messages may contain the placeholders {field}, {rule} and {param}.
+func (e *FieldError) Translate(messages map[string]string) string {
+	msg, ok := messages[e.Rule]
+	if !ok {
+		if msg, ok = DefaultValidationMessages[e.Rule]; !ok {
+			msg = DefaultValidationMessages[""]
+		}
+	}
+	return strings.NewReplacer("{field}", e.Field, "{rule}", e.Rule, "{param}", e.Param).Replace(msg)
+}
+
+// ValidationErrors is returned by Bind and its variants and by ValidateStruct when fields fail
+// their validate tags. It matches ErrValidation with errors.Is.
+type ValidationErrors []*FieldError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether target is ErrValidation.
+func (errs ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Translate returns the messages of the errors in messages, see FieldError.Translate.
+func (errs ValidationErrors) Translate(messages map[string]string) []string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Translate(messages)
+	}
+	return msgs
+}
+
+// DefaultValidationMessages are the English messages of the built-in rules. The message for
+// the empty rule is used for rules without one, such as custom rules.
+var DefaultValidationMessages = map[string]string{
+	"":         "field '{field}' failed rule '{rule}'",
+	"required": "field '{field}' is required",
+	"min":      "field '{field}' must be at least {param}",
+	"max":      "field '{field}' must be at most {param}",
+	"len":      "field '{field}' must have length {param}",
+	"gt":       "field '{field}' must be greater than {param}",
+	"lt":       "field '{field}' must be less than {param}",
+	"email":    "field '{field}' must be a valid email address",
+	"url":      "field '{field}' must be a valid URL",
+	"uuid":     "field '{field}' must be a valid UUID",
+	"oneof":    "field '{field}' must be one of [{param}]",
+	"regex":    "field '{field}' must match {param}",
+	"eqfield":  "field '{field}' must equal field '{param}'",
+	"nefield":  "field '{field}' must not equal field '{param}'",
+}
+
+// ValidationRule reports whether value satisfies a rule, where param is the text after "="
+// in the tag. Pointers are dereferenced before rules are applied.
+type ValidationRule func(value reflect.Value, param string) bool
+
+var (
+	rulesMu         sync.RWMutex
+	validationRules = map[string]ValidationRule{
+		"min":   func(v reflect.Value, p string) bool { return compare(v, p, func(a, b float64) bool { return a >= b }) },
+		"max":   func(v reflect.Value, p string) bool { return compare(v, p, func(a, b float64) bool { return a <= b }) },
+		"len":   func(v reflect.Value, p string) bool { return compare(v, p, func(a, b float64) bool { return a == b }) },
+		"gt":    func(v reflect.Value, p string) bool { return compare(v, p, func(a, b float64) bool { return a > b }) },
+		"lt":    func(v reflect.Value, p string) bool { return compare(v, p, func(a, b float64) bool { return a < b }) },
+		"email": isEmail,
+		"url":   isURL,
+		"uuid": func(v reflect.Value, _ string) bool {
+			return v.Kind() == reflect.String && uuidPattern.MatchString(v.String())
+		},
+		"oneof": isOneOf,
+		"regex": matchesRegex,
+	}
+
+	// paramCheckers validate the params of built-in rules, so that invalid tags are
+	// reported as errors instead of failing every value.
+	paramCheckers = map[string]func(param string) error{
+		"min":   checkNumber,
+		"max":   checkNumber,
+		"len":   checkNumber,
+		"gt":    checkNumber,
+		"lt":    checkNumber,
+		"regex": func(p string) error { _, err := compileRegex(p); return err },
+	}
+)
+
+// StrictValidation makes ValidateStruct report unknown rules in validate tags as errors.
+// By default they are ignored, so that a tag naming a rule that is not registered does not
+// fail every request. Set it before validating, e.g. in tests, to catch typos in tags.
+var StrictValidation bool
+
+// RegisterValidation adds a rule that validate tags can use by name, e.g.
+//
+//	amaro.RegisterValidation("even", func(v reflect.Value, _ string) bool {
+//		return v.CanInt() && v.Int()%2 == 0
+//	})
+//
+// It replaces a rule of the same name. "required", "omitempty", "dive", "eqfield" and
+// "nefield" are handled by the validator itself and cannot be replaced.
+func RegisterValidation(name string, rule ValidationRule) {
+	switch name {
+	case "", "required", "omitempty", "dive", "eqfield", "nefield":
+		panic(fmt.Sprintf("RegisterValidation: reserved rule name %q", name))
+	}
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	validationRules[name] = rule
+	delete(paramCheckers, name)
+}
+
+// ValidateStruct validates v, a struct or a pointer to one, against its validate tags and
+// returns ValidationErrors if any field fails. Rules are separated by commas:
+//
+//	Name   string            `validate:"required,min=2,max=64"`
+//	Email  string            `validate:"omitempty,email"`
+//	Role   string            `validate:"oneof=admin editor viewer"`
+//	Code   string            `validate:"regex=^[A-Z]{3}-[0-9]+$"`
+//	Retype string            `validate:"eqfield=Password"`
+//	Tags   []string          `validate:"max=5,dive,min=1"`
+//	Labels map[string]string `validate:"dive,required"`
+//
+// min, max, len, gt and lt compare numbers, or the length of strings, slices and maps.
+// Rules after dive apply to each element of a slice, array or map. Nested structs, including
+// those reached through dive, are validated recursively; a struct that refers back to one it is
+// nested in is not validated again. Because a pattern may contain commas, regex takes the rest
+// of the tag. Unknown rules are ignored unless StrictValidation is set. An invalid tag, such as
+// a malformed regex or a min that is not a number, is reported as a plain error rather than a
+// validation failure.
+func ValidateStruct(v interface{}) error {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil // validation only works on structs
+	}
+
+	vd := &validation{}
+	if err := vd.validateFields(val, ""); err != nil {
+		return err
+	}
+	if len(vd.errs) > 0 {
+		return vd.errs
+	}
+	return nil
+}
+
+// validation holds the state of one ValidateStruct call.
+type validation struct {
+	errs ValidationErrors
+
+	// nesting holds the structs and maps being validated, so that cycles end.
+	nesting map[visit]bool
+}
+
+// visit identifies a struct or map by its address and type; a struct and its first field
+// share an address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// enter reports whether the struct or map v is not already being validated further up, and
+// marks it as being validated until leave is called.
+func (vd *validation) enter(v reflect.Value) (leave func(), ok bool) {
+	var key visit
+	switch {
+	case v.Kind() == reflect.Map:
+		key = visit{v.Pointer(), v.Type()}
+	case v.CanAddr():
+		key = visit{v.Addr().Pointer(), v.Type()}
+	default:
+		// A copy can only lead back to itself through a pointer, slice or map, and those are tracked
+		return func() {}, true
+	}
+	if vd.nesting[key] {
+		return nil, false
+	}
+	if vd.nesting == nil {
+		vd.nesting = map[visit]bool{}
+	}
+	vd.nesting[key] = true
+	return func() { delete(vd.nesting, key) }, true
+}
+
+// validateFields validates the fields of the struct val, prefixing their paths with prefix.
+func (vd *validation) validateFields(val reflect.Value, prefix string) error {
+	leave, ok := vd.enter(val)
+	if !ok {
+		return nil
+	}
+	defer leave()
+
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		if err := vd.validateValue(val.Field(i), val, prefix+sf.Name, splitRules(tag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitRules splits a validate tag into its rules. regex takes the rest of the tag.
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regex=") {
+			return append(rules, tag)
+		}
+		rule, rest, _ := strings.Cut(tag, ",")
+		rules = append(rules, rule)
+		tag = rest
+	}
+	return rules
+}
+
+// validateValue applies rules to v, the field at path of the struct parent, then descends into
+// v if it is a struct.
+func (vd *validation) validateValue(v reflect.Value, parent reflect.Value, path string, rules []string) error {
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		fail := func() {
+			vd.errs = append(vd.errs, &FieldError{Field: path, Rule: name, Param: param, Value: v.Interface()})
+		}
+
+		switch name {
+		case "":
+			continue
+		case "required":
+			if v.IsZero() {
+				fail()
+				return nil
+			}
+			continue
+		case "omitempty":
+			if v.IsZero() {
+				return nil
+			}
+			continue
+		case "eqfield", "nefield":
+			if !parent.IsValid() {
+				return fmt.Errorf("field %s: %s only applies to struct fields", path, name)
+			}
+			other := parent.FieldByName(param)
+			if !other.IsValid() {
+				return fmt.Errorf("field %s: %s: unknown field %q", path, name, param)
+			}
+			if reflect.DeepEqual(v.Interface(), other.Interface()) != (name == "eqfield") {
+				fail()
+			}
+			continue
+		}
+
+		elem := indirect(v)
+		if !elem.IsValid() {
+			return nil // nil pointers only fail required
+		}
+		if name == "dive" {
+			return vd.validateElems(elem, path, rules[i+1:])
+		}
+
+		rulesMu.RLock()
+		check, ok := validationRules[name]
+		checkParam := paramCheckers[name]
+		rulesMu.RUnlock()
+		if !ok {
+			if StrictValidation {
+				return fmt.Errorf("field %s: unknown validation rule %q", path, name)
+			}
+			continue
+		}
+		if checkParam != nil {
+			if err := checkParam(param); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+		}
+		if !check(elem, param) {
+			fail()
+		}
+	}
+
+	if elem := indirect(v); elem.IsValid() && elem.Kind() == reflect.Struct {
+		return vd.validateFields(elem, path+".")
+	}
+	return nil
+}
+
+// validateElems applies rules to each element of the slice, array or map v.
+func (vd *validation) validateElems(v reflect.Value, path string, rules []string) error {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := vd.validateValue(v.Index(i), reflect.Value{}, fmt.Sprintf("%s[%d]", path, i), rules); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are copies, so a map holding the struct it is a field of is tracked itself
+		leave, ok := vd.enter(v)
+		if !ok {
+			return nil
+		}
+		defer leave()
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := vd.validateValue(iter.Value(), reflect.Value{}, fmt.Sprintf("%s[%v]", path, iter.Key()), rules); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("field %s: dive: %s is not a slice, array or map", path, v.Type())
+	}
+	return nil
+}
+
+// indirect dereferences pointers and interfaces, returning an invalid Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// compare compares the number v, or the length of the string, slice or map v, with param.
+// Values of other kinds pass.
+func compare(v reflect.Value, param string, ok func(a, b float64) bool) bool {
+	var n float64
+	switch v.Kind() {
+	case reflect.String:
+		n = float64(utf8.RuneCountInString(v.String()))
+	case reflect.Array, reflect.Slice, reflect.Map:
+		n = float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return true
+	}
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	return ok(n, limit)
+}
+
+// checkNumber reports an error if param is not a number.
+func checkNumber(param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return fmt.Errorf("invalid number %q", param)
+	}
+	return nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func isEmail(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
+
+func isURL(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.Parse(v.String())
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+// isOneOf reports whether v is one of the space-separated values in param.
+func isOneOf(v reflect.Value, param string) bool {
+	s := fmt.Sprint(v.Interface())
+	for _, allowed := range strings.Fields(param) {
+		if s == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+var regexCache sync.Map // pattern -> *regexp.Regexp
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("regex: %w", err)
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+func matchesRegex(v reflect.Value, pattern string) bool {
+	re, err := compileRegex(pattern)
+	return err == nil && v.Kind() == reflect.String && re.MatchString(v.String())
+}
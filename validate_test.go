@@ -0,0 +1,50 @@
+package amaro_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/buildwithgo/amaro"
+	"github.com/buildwithgo/amaro/routers"
+)
+
+func TestValidate(t *testing.T) {
+	app := amaro.New(amaro.WithRouter(routers.NewTrieRouter()))
+	handler := func(c *amaro.Context) error { return nil }
+
+	app.GET("/users/:id", handler)
+	app.Handle(http.MethodGet, "/users", handler, amaro.WithName("users"))
+	if err := app.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Errors ignored by the caller are still collected
+	app.GET("/users/{id}", handler)
+	app.Group("/files").GET("/*path/raw", handler)
+	app.Handle(http.MethodGet, "/people", handler, amaro.WithName("users"))
+	fsys := fstest.MapFS{"index.html": {Data: []byte("index")}}
+	app.StaticFS("/assets", fsys)
+	app.StaticFS("/assets", fsys)
+
+	err := app.Validate()
+	if !errors.Is(err, amaro.ErrRouteConflict) {
+		t.Fatalf("Expected route conflict, got %v", err)
+	}
+	var re *amaro.RouteError
+	if !errors.As(err, &re) || re.Method != http.MethodGet || re.Path != "/users/{id}" {
+		t.Errorf("Expected RouteError for GET /users/{id}, got %v", re)
+	}
+	for _, want := range []string{"/files/*path/raw", `route name "users"`, "GET /assets", "HEAD /assets/*filepath"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got:\n%v", want, err)
+		}
+	}
+
+	// Run refuses to start
+	if err := app.Run("0"); err == nil || !errors.Is(err, amaro.ErrRouteConflict) {
+		t.Errorf("Expected Run to fail validation, got %v", err)
+	}
+}
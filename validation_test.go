@@ -0,0 +1,202 @@
+package amaro_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buildwithgo/amaro"
+)
+
+type testAddress struct {
+	Street string `validate:"required"`
+	Zip    string `validate:"len=5"`
+}
+
+type testSignup struct {
+	Email    string `validate:"required,email"`
+	Website  string `validate:"omitempty,url"`
+	ID       string `validate:"uuid"`
+	Role     string `validate:"oneof=admin editor viewer"`
+	Code     string `validate:"regex=^[A-Z]{2,3}-[0-9]+$"`
+	Age      int    `validate:"gt=17,lt=130"`
+	Password string `validate:"min=8"`
+	Retype   string `validate:"eqfield=Password"`
+	Username string `validate:"nefield=Password"`
+	Address  testAddress
+	Previous *testAddress
+	Tags     []string          `validate:"max=3,dive,min=2"`
+	Labels   map[string]string `validate:"dive,required"`
+	Contacts []testAddress     `validate:"dive"`
+}
+
+func validSignup() testSignup {
+	return testSignup{
+		Email:    "ada@example.com",
+		ID:       "0f8fad5b-d9cb-469f-a165-70867728950e",
+		Role:     "editor",
+		Code:     "AB-12",
+		Age:      36,
+		Password: "correct horse",
+		Retype:   "correct horse",
+		Username: "ada",
+		Address:  testAddress{Street: "Main St", Zip: "12345"},
+		Tags:     []string{"go", "web"},
+		Labels:   map[string]string{"env": "prod"},
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	valid := validSignup()
+	if err := amaro.ValidateStruct(&valid); err != nil {
+		t.Fatalf("Expected valid struct, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *testSignup)
+		field  string
+		rule   string
+		param  string
+	}{
+		{"Required", func(s *testSignup) { s.Email = "" }, "Email", "required", ""},
+		{"Email", func(s *testSignup) { s.Email = "Ada <ada@example.com>" }, "Email", "email", ""},
+		{"URL", func(s *testSignup) { s.Website = "example.com" }, "Website", "url", ""},
+		{"UUID", func(s *testSignup) { s.ID = "0f8fad5b" }, "ID", "uuid", ""},
+		{"OneOf", func(s *testSignup) { s.Role = "root" }, "Role", "oneof", "admin editor viewer"},
+		{"Regex", func(s *testSignup) { s.Code = "ABCD-1" }, "Code", "regex", "^[A-Z]{2,3}-[0-9]+$"},
+		{"GT", func(s *testSignup) { s.Age = 17 }, "Age", "gt", "17"},
+		{"LT", func(s *testSignup) { s.Age = 130 }, "Age", "lt", "130"},
+		{"MinRunes", func(s *testSignup) { s.Password, s.Retype = "пароль", "пароль" }, "Password", "min", "8"},
+		{"EqField", func(s *testSignup) { s.Retype = "battery staple" }, "Retype", "eqfield", "Password"},
+		{"NeField", func(s *testSignup) { s.Username = s.Password }, "Username", "nefield", "Password"},
+		{"Nested", func(s *testSignup) { s.Address.Street = "" }, "Address.Street", "required", ""},
+		{"Len", func(s *testSignup) { s.Address.Zip = "123" }, "Address.Zip", "len", "5"},
+		{"NestedPointer", func(s *testSignup) { s.Previous = &testAddress{Zip: "12345"} }, "Previous.Street", "required", ""},
+		{"SliceLength", func(s *testSignup) { s.Tags = []string{"a1", "b2", "c3", "d4"} }, "Tags", "max", "3"},
+		{"DiveSlice", func(s *testSignup) { s.Tags = []string{"go", "x"} }, "Tags[1]", "min", "2"},
+		{"DiveMap", func(s *testSignup) { s.Labels["team"] = "" }, "Labels[team]", "required", ""},
+		{"DiveStruct", func(s *testSignup) { s.Contacts = []testAddress{{Street: "Elm St"}} }, "Contacts[0].Zip", "len", "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := validSignup()
+			tt.modify(&s)
+			err := amaro.ValidateStruct(&s)
+			if !errors.Is(err, amaro.ErrValidation) {
+				t.Fatalf("Expected ErrValidation, got %v", err)
+			}
+			var errs amaro.ValidationErrors
+			if !errors.As(err, &errs) || len(errs) != 1 {
+				t.Fatalf("Expected a single field error, got %v", err)
+			}
+			if got := errs[0]; got.Field != tt.field || got.Rule != tt.rule || got.Param != tt.param {
+				t.Errorf("Expected %s %s=%s, got %s %s=%s", tt.field, tt.rule, tt.param, got.Field, got.Rule, got.Param)
+			}
+		})
+	}
+}
+
+func TestValidationErrorsTranslate(t *testing.T) {
+	var s struct {
+		Name string `validate:"required"`
+		Age  int    `validate:"min=18"`
+		Nick string `validate:"lowercase"`
+	}
+	amaro.RegisterValidation("lowercase", func(v reflect.Value, _ string) bool {
+		return v.Kind() == reflect.String && v.String() == strings.ToLower(v.String())
+	})
+	s.Nick = "Ada"
+
+	var errs amaro.ValidationErrors
+	if !errors.As(amaro.ValidateStruct(&s), &errs) {
+		t.Fatal("Expected ValidationErrors")
+	}
+	want := "field 'Name' is required; field 'Age' must be at least 18; field 'Nick' failed rule 'lowercase'"
+	if errs.Error() != want {
+		t.Errorf("Expected %q, got %q", want, errs.Error())
+	}
+
+	got := errs.Translate(map[string]string{
+		"required":  "{field} ist erforderlich",
+		"min":       "{field} muss mindestens {param} sein",
+		"lowercase": "{field} muss kleingeschrieben sein",
+	})
+	wantDE := []string{"Name ist erforderlich", "Age muss mindestens 18 sein", "Nick muss kleingeschrieben sein"}
+	if !reflect.DeepEqual(got, wantDE) {
+		t.Errorf("Expected %q, got %q", wantDE, got)
+	}
+}
+
+func TestValidateStructInvalidTag(t *testing.T) {
+	amaro.StrictValidation = true
+	defer func() { amaro.StrictValidation = false }()
+
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"UnknownRule", &struct {
+			Inner struct {
+				Name string `validate:"bogus"`
+			}
+		}{}},
+		{"BadRegex", &struct {
+			Name string `validate:"regex=["`
+		}{}},
+		{"BadNumber", &struct {
+			Name string `validate:"min=abc"`
+		}{}},
+		{"UnknownField", &struct {
+			Name string `validate:"eqfield=Missing"`
+		}{}},
+		{"DiveScalar", &struct {
+			Name string `validate:"dive,required"`
+		}{Name: "ada"}},
+	}
+	for _, tt := range tests {
+		err := amaro.ValidateStruct(tt.v)
+		if err == nil || errors.Is(err, amaro.ErrValidation) {
+			t.Errorf("%s: Expected a tag error, got %v", tt.name, err)
+		}
+	}
+	if err := amaro.ValidateStruct(tests[0].v); !strings.HasPrefix(err.Error(), "field Inner.Name: ") {
+		t.Errorf("Expected the error to name the field, got %q", err)
+	}
+}
+
+func TestValidateStructUnknownRule(t *testing.T) {
+	v := &struct {
+		Name string `validate:"required,bogus,min=2"`
+	}{Name: "a"}
+	var errs amaro.ValidationErrors
+	if err := amaro.ValidateStruct(v); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Rule != "min" {
+		t.Errorf("Expected the unknown rule to be ignored, got %v", err)
+	}
+}
+
+type treeNode struct {
+	Name     string `validate:"required"`
+	Parent   *treeNode
+	Children []*treeNode         `validate:"dive"`
+	Links    map[string]treeNode `validate:"dive"`
+}
+
+func TestValidateStructCycles(t *testing.T) {
+	root := &treeNode{Name: "root"}
+	child := &treeNode{Parent: root}
+	root.Children = []*treeNode{child, root}
+	root.Parent = root
+	root.Links = map[string]treeNode{}
+	root.Links["self"] = treeNode{Name: "link", Links: root.Links}
+
+	var errs amaro.ValidationErrors
+	if err := amaro.ValidateStruct(root); !errors.As(err, &errs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	// The child is reached through Children only; Parent leads back to root
+	if len(errs) != 1 || errs[0].Field != "Children[0].Name" {
+		t.Errorf("Expected one error for Children[0].Name, got %v", errs)
+	}
+}